@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	errTimeout      = errors.New("timeout")
+	errDisconnected = errors.New("disconnected")
+	// errTooMany is a login the server refused because too many clients
+	// are connected from the same address.
+	errTooMany = errors.New("too many connections from one address")
+)
+
+// tooManyConnections is how the server refuses a connection over its limit
+// for one address.
+const tooManyConnections = "There are too many connections from your address."
+
+// refusal is an answer that means the server did not do what was asked.
+type refusal struct{ line string }
+
+func (r refusal) Error() string { return r.line }
+
+// run is what the clients of one run share.
+type run struct {
+	addr    string
+	think   time.Duration
+	timeout time.Duration
+	mix     []string
+	stats   *stats
+	names   []string
+
+	mu     sync.Mutex
+	online []bool // which clients are logged in, for picking whom to whisper to
+}
+
+// newRun prepares a run of clients against addr, named prefix followed by
+// a number, that pick actions from mix. The think time and timeout are
+// left for the caller to set.
+func newRun(addr, prefix string, clients int, mix []string) *run {
+	r := &run{
+		addr:   addr,
+		mix:    mix,
+		stats:  newStats(),
+		online: make([]bool, clients),
+		names:  make([]string, clients),
+	}
+	for i := range r.names {
+		r.names[i] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return r
+}
+
+// play starts the clients over the ramp time and waits until they have run
+// for the duration.
+func (r *run) play(seed int64, ramp, duration time.Duration) {
+	end := time.Now().Add(ramp + duration)
+	var wg sync.WaitGroup
+	for i, name := range r.names {
+		c := &client{
+			run:    r,
+			index:  i,
+			name:   name,
+			random: rand.New(rand.NewSource(seed + int64(i))),
+		}
+		delay := ramp * time.Duration(i) / time.Duration(len(r.names))
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(delay)
+			c.play(end)
+		}()
+	}
+	wg.Wait()
+}
+
+func (r *run) setOnline(index int, online bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.online[index] = online
+}
+
+// pickTarget picks a client other than self that is logged in.
+func (r *run) pickTarget(random *rand.Rand, self int) (string, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var candidates []string
+	for i, online := range r.online {
+		if online && i != self {
+			candidates = append(candidates, r.names[i])
+		}
+	}
+	if len(candidates) == 0 {
+		return "", false
+	}
+	return candidates[random.Intn(len(candidates))], true
+}
+
+// client is one simulated player. Only its own goroutine uses it.
+type client struct {
+	run    *run
+	index  int
+	name   string
+	random *rand.Rand
+
+	conn  net.Conn
+	lines chan string // lines read, closed when the connection closes
+	exits []string    // the exits of the room the client is in, nil if not known yet
+	said  int         // how many lines the client has said, to tell them apart
+}
+
+// play logs in and does actions from the mix until end, then quits. A
+// client the server turns away for having too many connections gives up,
+// since it would only be turned away again.
+func (c *client) play(end time.Time) {
+	for time.Now().Before(end) {
+		if c.conn == nil {
+			if !c.login() {
+				return
+			}
+		} else {
+			c.act(c.run.mix[c.random.Intn(len(c.run.mix))])
+		}
+		c.pause()
+	}
+	if c.conn != nil {
+		c.act("quit")
+	}
+}
+
+// pause waits for around the think time, so the clients do not move in step.
+func (c *client) pause() {
+	if c.run.think > 0 {
+		time.Sleep(c.run.think/2 + time.Duration(c.random.Int63n(int64(c.run.think))))
+	}
+}
+
+// login connects and logs in, recording how long it took. It returns false
+// if the server refused the connection for having too many from one address.
+func (c *client) login() bool {
+	start := time.Now()
+	conn, err := net.DialTimeout("tcp", c.run.addr, c.run.timeout)
+	if err != nil {
+		c.run.stats.record("login", 0, err)
+		return true
+	}
+	c.conn, c.lines, c.exits = conn, make(chan string, 256), nil
+	go c.read(conn, c.lines)
+
+	line, err := c.await(nil, "Who are you?", tooManyConnections)
+	if err == nil && strings.HasPrefix(line, tooManyConnections) {
+		c.run.stats.record("login", 0, errTooMany)
+		c.disconnect()
+		return false
+	}
+	if err == nil {
+		err = c.send(c.name)
+	}
+	if err == nil {
+		_, err = c.await(
+			[]string{"is already taken", "Names must be", "could not be loaded", "banned"},
+			fmt.Sprintf("Welcome, %s!", c.name))
+	}
+	c.run.stats.record("login", time.Since(start), err)
+	if err != nil {
+		c.disconnect()
+		return true
+	}
+	c.run.setOnline(c.index, true)
+	return true
+}
+
+// act does an action and records how long the server took to answer it.
+func (c *client) act(action string) {
+	var input string
+	var accept, reject []string
+	switch action {
+	case "look":
+		input, accept = "/look", []string{"Exits: "}
+	case "move":
+		if c.exits == nil {
+			c.act("look")
+			return
+		}
+		if len(c.exits) == 0 {
+			c.act("say")
+			return
+		}
+		direction := c.exits[c.random.Intn(len(c.exits))]
+		input, accept, reject = "/"+direction, []string{"Exits: "}, []string{"You can't go that way."}
+	case "say":
+		c.said++
+		text := fmt.Sprintf("Hello number %d", c.said)
+		input, accept = "/say "+text, []string{fmt.Sprintf("%s says: %s", c.name, text)}
+	case "whisper":
+		target, found := c.run.pickTarget(c.random, c.index)
+		if !found {
+			c.act("say")
+			return
+		}
+		c.said++
+		text := fmt.Sprintf("Psst number %d", c.said)
+		input = fmt.Sprintf("/whisper %s %s", target, text)
+		accept = []string{fmt.Sprintf("You whispered to %s: %s", target, text), target + " is offline."}
+		reject = []string{"not found.", "could not be delivered.", "has too many messages waiting.", "is ignoring you."}
+	case "quit":
+		input, accept = "/quit", []string{"Goodbye!"}
+	}
+
+	start := time.Now()
+	err := c.send(input)
+	var line string
+	if err == nil {
+		line, err = c.await(reject, accept...)
+	}
+	c.run.stats.record(action, time.Since(start), err)
+	switch {
+	case err != nil || action == "quit":
+		c.disconnect()
+	case strings.HasPrefix(line, "Exits: "):
+		c.exits = parseExits(line)
+	}
+}
+
+// parseExits returns the directions in a room description's exits line.
+func parseExits(line string) []string {
+	list := strings.TrimPrefix(line, "Exits: ")
+	if list == "none" {
+		return []string{}
+	}
+	return strings.Split(list, ", ")
+}
+
+func (c *client) send(line string) error {
+	c.conn.SetWriteDeadline(time.Now().Add(c.run.timeout))
+	if _, err := fmt.Fprintf(c.conn, "%s\n", line); err != nil {
+		return errDisconnected
+	}
+	return nil
+}
+
+// await reads lines until one starts with one of accept and returns it. A
+// line containing one of reject is returned as a refusal. Lines meant for
+// others, such as what other players say, are skipped.
+func (c *client) await(reject []string, accept ...string) (string, error) {
+	timer := time.NewTimer(c.run.timeout)
+	defer timer.Stop()
+	for {
+		select {
+		case line, ok := <-c.lines:
+			if !ok {
+				return "", errDisconnected
+			}
+			for _, prefix := range accept {
+				if strings.HasPrefix(line, prefix) {
+					return line, nil
+				}
+			}
+			for _, text := range reject {
+				if strings.Contains(line, text) {
+					return line, refusal{line}
+				}
+			}
+		case <-timer.C:
+			return "", errTimeout
+		}
+	}
+}
+
+// disconnect drops the connection, so the next turn logs in afresh.
+func (c *client) disconnect() {
+	c.run.setOnline(c.index, false)
+	c.conn.Close()
+	for range c.lines {
+		// Wait for the reader to finish, so its lines cannot be mistaken
+		// for the next connection's
+	}
+	c.conn, c.lines = nil, nil
+}
+
+// read queues the lines the server sends, without surrounding whitespace.
+func (c *client) read(conn net.Conn, lines chan<- string) {
+	defer close(lines)
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		lines <- strings.TrimSpace(line)
+	}
+}
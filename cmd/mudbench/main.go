@@ -0,0 +1,124 @@
+// Command mudbench loads a running server with simulated telnet clients.
+// Each client logs in and then, until the run ends, picks actions such as
+// moving, chatting and whispering at random from a weighted mix. The time
+// every action takes to be answered is reported as percentiles, along with
+// how often actions failed.
+//
+// The clients all connect from one address, and by default there are as
+// many of them as the server accepts from one address. To run more, start
+// the server with a higher -max-connections-per-ip, or 0 for no limit.
+// Logins the server refuses for having too many connections are reported
+// apart from the other results, since they measure nothing.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"mud/telnet"
+)
+
+// actions are what a client can do, in the order they are reported.
+var actions = []string{"login", "look", "move", "say", "whisper", "quit"}
+
+// defaultMix is how often each action is picked, as weights.
+const defaultMix = "look=1,move=4,say=3,whisper=2,quit=1"
+
+func main() {
+	addr := flag.String("addr", "localhost:2323", "address of the server")
+	clients := flag.Int("clients", telnet.DefaultMaxConnectionsPerIP, "how many clients to run at once")
+	duration := flag.Duration("duration", 30*time.Second, "how long to run for")
+	think := flag.Duration("think", 500*time.Millisecond, "average pause between a client's actions")
+	ramp := flag.Duration("ramp", 5*time.Second, "how long to spread the clients' first logins over")
+	timeout := flag.Duration("timeout", 5*time.Second, "how long to wait for an answer before an action fails")
+	mixFlag := flag.String("mix", defaultMix, "weights of the actions look, move, say, whisper and quit; quit logs back in")
+	prefix := flag.String("prefix", "Bench", "prefix of the clients' names, which are followed by a number")
+	seed := flag.Int64("seed", 0, "seed for the clients' choices, or 0 to pick one")
+	flag.Parse()
+
+	mix, err := parseMix(*mixFlag)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Invalid -mix:", err)
+		os.Exit(2)
+	}
+	if *clients < 1 {
+		fmt.Fprintln(os.Stderr, "-clients must be at least 1")
+		os.Exit(2)
+	}
+	if name := fmt.Sprintf("%s%d", *prefix, *clients-1); !validName(name) {
+		fmt.Fprintf(os.Stderr, "Names such as %s are not valid; names must be 2 to 20 letters or digits\n", name)
+		os.Exit(2)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
+
+	run := newRun(*addr, *prefix, *clients, mix)
+	run.think, run.timeout = *think, *timeout
+
+	fmt.Printf("Running %d clients against %s for %s (seed %d)\n", *clients, *addr, *duration, *seed)
+	start := time.Now()
+	run.play(*seed, *ramp, *duration)
+
+	run.stats.report(os.Stdout, time.Since(start))
+	if run.stats.failed() {
+		os.Exit(1)
+	}
+}
+
+// parseMix parses weights such as "move=4,say=3" into a table to pick
+// actions from, in which each action appears as often as its weight.
+func parseMix(s string) ([]string, error) {
+	var mix []string
+	for _, setting := range strings.Split(s, ",") {
+		if setting == "" {
+			continue
+		}
+		action, weightText, found := strings.Cut(setting, "=")
+		if !found {
+			return nil, fmt.Errorf("expected action=weight, got %q", setting)
+		}
+		if action == "login" || !contains(actions, action) {
+			return nil, fmt.Errorf("unknown action %q", action)
+		}
+		weight, err := strconv.Atoi(weightText)
+		if err != nil || weight < 0 {
+			return nil, fmt.Errorf("invalid weight %q for %s", weightText, action)
+		}
+		for i := 0; i < weight; i++ {
+			mix = append(mix, action)
+		}
+	}
+	if len(mix) == 0 {
+		return nil, fmt.Errorf("no action has a weight")
+	}
+	sort.Strings(mix)
+	return mix, nil
+}
+
+// validName reports whether the server accepts name as a player's name.
+func validName(name string) bool {
+	if len(name) < 2 || len(name) > 20 {
+		return false
+	}
+	for _, r := range name {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
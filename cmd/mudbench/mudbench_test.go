@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mud/game"
+	"mud/mudtest"
+	"mud/telnet"
+)
+
+// benchmark runs clients against addr for a moment and returns their
+// report.
+func benchmark(t *testing.T, addr string, clients int) (*run, string) {
+	t.Helper()
+	mix, err := parseMix(defaultMix)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := newRun(addr, "Bench", clients, mix)
+	r.think, r.timeout = 20*time.Millisecond, 5*time.Second
+	r.play(1, 100*time.Millisecond, time.Second)
+	var report strings.Builder
+	r.stats.report(&report, time.Second)
+	return r, report.String()
+}
+
+func TestSmoke(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	r, report := benchmark(t, s.Addr, 5)
+	if r.stats.failed() {
+		t.Errorf("Expected no failures, got:\n%s", report)
+	}
+	for _, action := range []string{"login", "look", "move", "say"} {
+		if len(r.stats.latencies[action]) == 0 {
+			t.Errorf("Expected some %s actions, got:\n%s", action, report)
+		}
+	}
+}
+
+func TestTurnedAway(t *testing.T) {
+	t.Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := game.NewGame(game.WithDataDir(t.TempDir()))
+	server := telnet.NewServer(g)
+	server.MaxConnectionsPerIP = 2
+	go server.Serve(listener)
+	defer g.Shutdown()
+	defer listener.Close()
+
+	r, report := benchmark(t, listener.Addr().String(), 4)
+	if r.stats.turnedAway != 2 {
+		t.Errorf("Expected 2 clients turned away, got:\n%s", report)
+	}
+	if len(r.stats.errors) > 0 {
+		t.Errorf("Expected the clients turned away not to count as errors, got:\n%s", report)
+	}
+	if !strings.Contains(report, "2 clients were turned away") {
+		t.Errorf("Expected the report to say clients were turned away, got:\n%s", report)
+	}
+}
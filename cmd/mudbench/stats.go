@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// stats collects how long actions took and how they failed.
+type stats struct {
+	mu         sync.Mutex
+	latencies  map[string][]time.Duration // of the actions that succeeded
+	errors     map[string]map[string]int  // counts by action and kind of error
+	examples   map[string]string          // the first error of each kind, by action and kind
+	turnedAway int                        // clients refused for too many connections from one address
+}
+
+func newStats() *stats {
+	return &stats{
+		latencies: make(map[string][]time.Duration),
+		errors:    make(map[string]map[string]int),
+		examples:  make(map[string]string),
+	}
+}
+
+// record notes an action that took d, or failed with err.
+func (s *stats) record(action string, d time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err == nil {
+		s.latencies[action] = append(s.latencies[action], d)
+		return
+	}
+	if errors.Is(err, errTooMany) {
+		s.turnedAway++
+		return
+	}
+	kind := "error"
+	var refused refusal
+	switch {
+	case errors.Is(err, errTimeout):
+		kind = "timeout"
+	case errors.Is(err, errDisconnected):
+		kind = "disconnected"
+	case errors.As(err, &refused):
+		kind = "refused"
+	}
+	if s.errors[action] == nil {
+		s.errors[action] = make(map[string]int)
+	}
+	s.errors[action][kind]++
+	if _, seen := s.examples[action+" "+kind]; !seen {
+		s.examples[action+" "+kind] = err.Error()
+	}
+}
+
+// failed reports whether any action failed or any client was turned away.
+func (s *stats) failed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.errors) > 0 || s.turnedAway > 0
+}
+
+// report writes a table of each action's latency percentiles and error
+// rate, followed by an example of each kind of error and how many clients
+// the server turned away.
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(table, "action\tcount\terrors\terror rate\tp50\tp90\tp99\tmax\t")
+	total, totalErrors := 0, 0
+	for _, action := range actions {
+		latencies := s.latencies[action]
+		failures := 0
+		for _, count := range s.errors[action] {
+			failures += count
+		}
+		count := len(latencies) + failures
+		if count == 0 {
+			continue
+		}
+		total += count
+		totalErrors += failures
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		fmt.Fprintf(table, "%s\t%d\t%d\t%.2f%%\t%s\t%s\t%s\t%s\t\n", action, count, failures,
+			100*float64(failures)/float64(count),
+			percentile(latencies, 50), percentile(latencies, 90), percentile(latencies, 99), percentile(latencies, 100))
+	}
+	table.Flush()
+
+	fmt.Fprintf(w, "\n%d actions in %s (%.1f per second), %d failed\n", total, elapsed.Round(time.Millisecond),
+		float64(total)/elapsed.Seconds(), totalErrors)
+	for _, action := range actions {
+		kinds := make([]string, 0, len(s.errors[action]))
+		for kind := range s.errors[action] {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		for _, kind := range kinds {
+			fmt.Fprintf(w, "  %s: %d %s, such as: %s\n", action, s.errors[action][kind], kind, s.examples[action+" "+kind])
+		}
+	}
+	if s.turnedAway > 0 {
+		fmt.Fprintf(w, "\n%d clients were turned away for too many connections from one address and took no part.\n"+
+			"Run fewer -clients, or start the server with a higher -max-connections-per-ip.\n", s.turnedAway)
+	}
+}
+
+// percentile returns the latency that p percent of the sorted latencies
+// are no slower than, or "-" if there are none.
+func percentile(sorted []time.Duration, p int) string {
+	if len(sorted) == 0 {
+		return "-"
+	}
+	i := (len(sorted)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return sorted[i].Round(10 * time.Microsecond).String()
+}
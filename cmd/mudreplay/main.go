@@ -0,0 +1,72 @@
+// Command mudreplay replays a recording made with the server's -record flag
+// against a fresh game and reports where the output differs from what was
+// recorded.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"mud/game"
+)
+
+func main() {
+	bareCommands := flag.Bool("bare", false, "parse input without a leading / as a command")
+	socialsFile := flag.String("socials", "", "JSON file with extra socials")
+	owner := flag.String("owner", "", "name of the player who owns the server; they log in with the password in $MUD_OWNER_PASSWORD")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] recording\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error opening the recording:", err)
+		os.Exit(1)
+	}
+	records, err := game.ReadRecording(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading the recording:", err)
+		os.Exit(1)
+	}
+
+	options := []game.Option{
+		game.WithBareCommands(*bareCommands),
+		game.WithOwner(*owner, os.Getenv("MUD_OWNER_PASSWORD")),
+	}
+	if *socialsFile != "" {
+		socials, err := game.LoadSocials(*socialsFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error loading socials:", err)
+			os.Exit(1)
+		}
+		options = append(options, game.WithSocials(socials))
+	}
+
+	// The replay starts from no saved data, and must not touch a real server's
+	dataDir, err := os.MkdirTemp("", "mudreplay")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error making a data directory:", err)
+		os.Exit(1)
+	}
+	options = append(options, game.WithDataDir(dataDir))
+
+	report, err := game.Replay(records, options...)
+	os.RemoveAll(dataDir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error replaying the recording:", err)
+		os.Exit(1)
+	}
+	if report != "" {
+		fmt.Print(report)
+		os.Exit(1)
+	}
+	fmt.Printf("Replayed %d records with no differences\n", len(records))
+}
@@ -0,0 +1,134 @@
+package game
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Account is the part of a player that outlives their session.
+type Account struct {
+	Name    string            `json:"name"`
+	Aliases map[string]string `json:"aliases,omitempty"`
+
+	// Channels the player rejoins on login. It is nil until the player
+	// first logs in, when they are put on the default channels.
+	Channels []string `json:"channels"`
+
+	// Tells are whispers that arrived while the player was offline.
+	Tells []Tell `json:"tells,omitempty"`
+
+	// Ignored holds the lowercased names of players whose messages are
+	// hidden from this player.
+	Ignored []string `json:"ignored,omitempty"`
+
+	Mail []Mail `json:"mail,omitempty"`
+
+	Title string `json:"title,omitempty"` // shown after the name in /who and /finger
+	Class string `json:"class,omitempty"`
+
+	Role Role `json:"role,omitempty"`
+
+	// Password is a hash made by hashPassword, or empty if the player has
+	// not set one. Staff cannot log in without one.
+	Password string `json:"password,omitempty"`
+
+	// Muted players cannot talk to others until MutedUntil, or until
+	// they are unmuted if it is zero.
+	Muted      bool      `json:"muted,omitempty"`
+	MutedUntil time.Time `json:"muted_until,omitempty"`
+
+	LastIP    string    `json:"last_ip,omitempty"`
+	Created   time.Time `json:"created"`
+	LastLogin time.Time `json:"last_login"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// defaultClass is the class of players who have not picked one.
+const defaultClass = "Adventurer"
+
+func (a *Account) class() string {
+	if a.Class == "" {
+		return defaultClass
+	}
+	return a.Class
+}
+
+// accountStore keeps accounts as one JSON file each in dir. Loaded accounts
+// are cached, so with an empty dir they still last for the server's lifetime.
+type accountStore struct {
+	dir      string
+	clock    Clock
+	accounts map[string]*Account // maps lowercased name to Account
+}
+
+func newAccountStore(dir string, clock Clock) *accountStore {
+	return &accountStore{dir: dir, clock: clock, accounts: make(map[string]*Account)}
+}
+
+// load returns the account for name, creating it if it does not exist yet.
+func (s *accountStore) load(name string) (*Account, error) {
+	key := strings.ToLower(name)
+	if account, exists := s.accounts[key]; exists {
+		return account, nil
+	}
+
+	account := &Account{Name: name}
+	if s.dir != "" {
+		if _, err := readJSONFile(s.path(key), account); err != nil {
+			return nil, fmt.Errorf("reading account %s: %w", name, err)
+		}
+	}
+	if account.Aliases == nil {
+		account.Aliases = make(map[string]string)
+	}
+	if account.Created.IsZero() {
+		account.Created = s.clock.Now()
+	}
+	s.accounts[key] = account
+	return account, nil
+}
+
+// exists reports whether an account has ever been created for name.
+func (s *accountStore) exists(name string) bool {
+	key := strings.ToLower(name)
+	if _, exists := s.accounts[key]; exists {
+		return true
+	}
+	if s.dir == "" {
+		return false
+	}
+	_, err := os.Stat(s.path(key))
+	return err == nil
+}
+
+// save writes the account to disk.
+func (s *accountStore) save(account *Account) error {
+	if s.dir == "" {
+		return nil
+	}
+	if err := writeJSONFile(s.path(strings.ToLower(account.Name)), account); err != nil {
+		return fmt.Errorf("saving account %s: %w", account.Name, err)
+	}
+	return nil
+}
+
+func (s *accountStore) path(key string) string {
+	return filepath.Join(s.dir, "accounts", key+".json")
+}
+
+// validName reports whether name can be used as a player name. Names become
+// file names, so they are restricted to letters and digits.
+func validName(name string) bool {
+	if len(name) < 2 || len(name) > 20 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isWordByte(name[i]) {
+			return false
+		}
+	}
+	return true
+}
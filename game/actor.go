@@ -0,0 +1,215 @@
+package game
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// An actor runs jobs one at a time on its own goroutine. Every room and
+// every channel has one, so players in different rooms are served side by
+// side instead of waiting on each other.
+//
+// Jobs run with the world lock held for reading. A job may change its own
+// room and the sessions in it, and read anything else; changes to another
+// room or channel are posted as jobs to that room's or channel's actor.
+// Jobs that change what everyone shares, such as logins and most staff
+// commands, take the lock exclusively instead, which pauses every other
+// actor while they run.
+type actor struct {
+	g         *Game
+	name      string // what the actor looks after, for health checks
+	wake      chan struct{}
+	busySince atomic.Int64 // when the current job started, in Unix nanoseconds, or 0
+
+	mu       sync.Mutex
+	queue    []job
+	stopping bool // no more jobs are taken; the actor ends once the queue is empty
+}
+
+// A job is a piece of work for an actor.
+type job struct {
+	// session is who the job is about, if anyone. Such a job runs on the
+	// actor of the session's room, following the session if it moves, and
+	// is dropped once the session has left the game.
+	session *Session
+	// exclusive reports whether the job needs the world lock to itself. It
+	// is called with the lock held for reading; nil means never.
+	exclusive func() bool
+	run       func() []OutputEvent
+}
+
+// always is the exclusive check of jobs that always need the world lock to
+// themselves.
+func always() bool { return true }
+
+func newActor(g *Game, name string) *actor {
+	a := &actor{g: g, name: name, wake: make(chan struct{}, 1)}
+	g.actorsMu.Lock()
+	g.actors[a] = true
+	g.actorsMu.Unlock()
+	go a.loop()
+	return a
+}
+
+// post queues a job. It never blocks, so actors can post to each other
+// while they hold the world lock.
+func (a *actor) post(j job) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.stopping {
+		return
+	}
+	a.queue = append(a.queue, j)
+	a.g.jobs.Add(1)
+	a.signal()
+}
+
+// stop ends the actor once the jobs already queued have run.
+func (a *actor) stop() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.stopping = true
+	a.signal()
+}
+
+// waiting returns how many jobs are queued.
+func (a *actor) waiting() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.queue)
+}
+
+func (a *actor) signal() {
+	select {
+	case a.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (a *actor) loop() {
+	defer func() {
+		a.g.actorsMu.Lock()
+		delete(a.g.actors, a)
+		a.g.actorsMu.Unlock()
+	}()
+	for {
+		j, ok := a.next()
+		if !ok {
+			return
+		}
+		a.busySince.Store(time.Now().UnixNano())
+		a.do(j)
+		a.busySince.Store(0)
+		a.g.jobs.Add(-1)
+	}
+}
+
+// next waits for the next job. It returns false once the actor has stopped.
+func (a *actor) next() (job, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for len(a.queue) == 0 {
+		if a.stopping {
+			return job{}, false
+		}
+		a.mu.Unlock()
+		<-a.wake
+		a.mu.Lock()
+	}
+	j := a.queue[0]
+	a.queue[0] = job{}
+	a.queue = a.queue[1:]
+	return j, true
+}
+
+// do runs a job and sends the events it produces, holding the world lock
+// for reading unless the job needs it exclusively.
+func (a *actor) do(j job) {
+	g := a.g
+	g.mu.RLock()
+	if !a.claim(j) {
+		g.mu.RUnlock()
+		return
+	}
+	if j.exclusive == nil || !j.exclusive() {
+		g.send(j.run())
+		g.mu.RUnlock()
+		return
+	}
+	g.mu.RUnlock()
+
+	// Anything may have happened to the session while the lock was free
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if a.claim(j) {
+		g.send(j.run())
+	}
+}
+
+// claim reports whether the job should run here. A job for a session that
+// has moved on is passed to the actor of its new room, and one for a
+// session still on its way into this room waits until it has arrived.
+func (a *actor) claim(j job) bool {
+	if j.session == nil {
+		return true
+	}
+	if a.g.sessions[j.session.ID] != j.session {
+		return false
+	}
+	home := j.session.home.Load()
+	if home.actor != a || home.Sessions[j.session.ID] != j.session {
+		// enter queues the arrival before the session's home changes, so
+		// requeuing puts the job after it
+		home.actor.post(j)
+		return false
+	}
+	return true
+}
+
+// post queues a job about the session on the actor of its room.
+func (s *Session) post(j job) {
+	j.session = s
+	s.home.Load().actor.post(j)
+}
+
+// offload runs slow work, such as hashing a password, on a goroutine of
+// its own instead of an actor, so it holds up neither the session's room
+// nor the world lock. The job the work returns is then posted for the
+// session. Until it has been posted the work counts as a job in progress.
+func (g *Game) offload(session *Session, work func() job) {
+	g.jobs.Add(1)
+	go func() {
+		defer g.jobs.Add(-1)
+		session.post(work())
+	}()
+}
+
+// inputQueue holds a session's input until the actor of its room gets to
+// it. Input is queued per session rather than posted with each job, so it
+// is handled in order even when the session moves between rooms.
+type inputQueue struct {
+	mu      sync.Mutex
+	pending []InputEvent
+}
+
+func (q *inputQueue) push(event InputEvent) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, event)
+}
+
+// peek returns the oldest input without taking it.
+func (q *inputQueue) peek() InputEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending[0]
+}
+
+func (q *inputQueue) pop() InputEvent {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	event := q.pending[0]
+	q.pending = q.pending[1:]
+	return event
+}
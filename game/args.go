@@ -0,0 +1,186 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ArgKind is the kind of value an argument accepts.
+type ArgKind int
+
+const (
+	ArgWord   ArgKind = iota // a single word, quoted if it contains spaces
+	ArgNumber                // a whole number
+	ArgTarget                // a reference such as "bob", "2.bo" or "all.bo"
+	ArgRest                  // everything that is left of the line
+)
+
+// ArgSpec describes one argument of a command.
+type ArgSpec struct {
+	Name     string
+	Kind     ArgKind
+	Optional bool
+}
+
+// Target is a parsed reference to one or more things by keyword.
+type Target struct {
+	Keyword string
+	Ordinal int  // which match to pick, starting at 1
+	All     bool // every match rather than one
+}
+
+// Args holds the arguments a command was called with.
+type Args struct {
+	Raw    string // the parameters exactly as typed
+	values map[string]any
+}
+
+// Has reports whether the named argument was given.
+func (a *Args) Has(name string) bool {
+	_, exists := a.values[name]
+	return exists
+}
+
+// String returns the named word or rest argument, or "" if it was not given.
+func (a *Args) String(name string) string {
+	value, _ := a.values[name].(string)
+	return value
+}
+
+// Int returns the named number argument, or 0 if it was not given.
+func (a *Args) Int(name string) int {
+	value, _ := a.values[name].(int)
+	return value
+}
+
+// Target returns the named target argument.
+func (a *Args) Target(name string) Target {
+	value, _ := a.values[name].(Target)
+	return value
+}
+
+// nextWord reads the word of line that starts at or after start, returning
+// it and where it ends in the line, or found false if only spaces are left.
+// Double quotes group words with spaces, and a backslash inside quotes
+// escapes the next character.
+func nextWord(line string, start int) (word string, end int, found bool, err error) {
+	var b strings.Builder
+	inWord, inQuotes := false, false
+
+	i := start
+	for ; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inQuotes && c == '\\' && i+1 < len(line):
+			i++
+			b.WriteByte(line[i])
+		case c == '"':
+			inQuotes = !inQuotes
+			inWord = true
+		case !inQuotes && (c == ' ' || c == '\t'):
+			if inWord {
+				return b.String(), i, true, nil
+			}
+		default:
+			b.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inQuotes {
+		return "", i, false, errors.New("Unterminated quote.")
+	}
+	return b.String(), i, inWord, nil
+}
+
+// parseArgs matches params against specs. Words are read one argument at a
+// time, and a rest argument takes what is left of params as typed, so
+// quotes in free text are kept as they are. The returned error is meant to
+// be shown to the player.
+func parseArgs(specs []ArgSpec, params string) (*Args, error) {
+	args := &Args{Raw: params, values: make(map[string]any)}
+
+	pos := 0
+	for _, spec := range specs {
+		if spec.Kind == ArgRest {
+			rest := strings.TrimSpace(params[pos:])
+			pos = len(params)
+			if rest != "" {
+				args.values[spec.Name] = rest
+			} else if !spec.Optional {
+				return nil, fmt.Errorf("Missing <%s>.", spec.Name)
+			}
+			continue
+		}
+
+		word, end, found, err := nextWord(params, pos)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			if spec.Optional {
+				continue
+			}
+			return nil, fmt.Errorf("Missing <%s>.", spec.Name)
+		}
+		pos = end
+
+		switch spec.Kind {
+		case ArgNumber:
+			n, err := strconv.Atoi(word)
+			if err != nil {
+				return nil, fmt.Errorf("<%s> must be a number.", spec.Name)
+			}
+			args.values[spec.Name] = n
+		case ArgTarget:
+			target, err := parseTarget(word)
+			if err != nil {
+				return nil, err
+			}
+			args.values[spec.Name] = target
+		default:
+			args.values[spec.Name] = word
+		}
+	}
+
+	if _, _, found, err := nextWord(params, pos); found || err != nil {
+		return nil, errors.New("Too many arguments.")
+	}
+	return args, nil
+}
+
+// parseTarget parses "keyword", "N.keyword", "all" and "all.keyword".
+func parseTarget(text string) (Target, error) {
+	prefix, keyword, found := strings.Cut(text, ".")
+	if !found {
+		if strings.EqualFold(text, "all") {
+			return Target{All: true}, nil
+		}
+		return Target{Keyword: strings.ToLower(text), Ordinal: 1}, nil
+	}
+	if keyword == "" {
+		return Target{}, fmt.Errorf("Nothing named after %q.", text)
+	}
+	if strings.EqualFold(prefix, "all") {
+		return Target{Keyword: strings.ToLower(keyword), All: true}, nil
+	}
+	ordinal, err := strconv.Atoi(prefix)
+	if err != nil || ordinal < 1 {
+		return Target{}, fmt.Errorf("%q is not a valid position.", prefix)
+	}
+	return Target{Keyword: strings.ToLower(keyword), Ordinal: ordinal}, nil
+}
+
+// usageFromArgs builds a usage line such as "/whisper <username> <message>".
+func usageFromArgs(name string, specs []ArgSpec) string {
+	parts := []string{"/" + name}
+	for _, spec := range specs {
+		if spec.Optional {
+			parts = append(parts, fmt.Sprintf("[<%s>]", spec.Name))
+		} else {
+			parts = append(parts, fmt.Sprintf("<%s>", spec.Name))
+		}
+	}
+	return strings.Join(parts, " ")
+}
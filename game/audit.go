@@ -0,0 +1,138 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// auditMemory is how many recent audit entries are kept for /audit.
+const auditMemory = 1000
+
+// AuditEntry records one administrative or security event.
+type AuditEntry struct {
+	Time   time.Time `json:"time"`
+	Actor  string    `json:"actor,omitempty"` // player who caused the event, if any
+	Action string    `json:"action"`
+	Target string    `json:"target,omitempty"` // player or address the event was about
+	Detail string    `json:"detail,omitempty"`
+	IP     string    `json:"ip,omitempty"` // address of the actor
+}
+
+// auditLog appends entries to a JSON lines file that is never rewritten,
+// and keeps the most recent ones in memory.
+type auditLog struct {
+	path    string // empty to keep the log in memory only
+	entries []AuditEntry
+}
+
+// newAuditLog opens the audit log in dir, reading back its recent entries.
+func newAuditLog(dir string) (*auditLog, error) {
+	a := &auditLog{}
+	if dir == "" {
+		return a, nil
+	}
+	a.path = filepath.Join(dir, "audit.log")
+
+	file, err := os.Open(a.path)
+	if os.IsNotExist(err) {
+		return a, nil
+	}
+	if err != nil {
+		return a, fmt.Errorf("reading audit log: %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return a, fmt.Errorf("reading audit log: %w", err)
+		}
+		a.remember(entry)
+	}
+	return a, scanner.Err()
+}
+
+func (a *auditLog) remember(entry AuditEntry) {
+	a.entries = append(a.entries, entry)
+	if len(a.entries) > auditMemory {
+		a.entries = a.entries[len(a.entries)-auditMemory:]
+	}
+}
+
+// record appends an entry to the log.
+func (a *auditLog) record(entry AuditEntry) error {
+	a.remember(entry)
+	if a.path == "" {
+		return nil
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encoding audit entry: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0o755); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	file, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("writing audit log: %w", err)
+	}
+	return nil
+}
+
+// search returns up to limit of the most recent entries mentioning text as
+// actor, target or action, oldest first.
+func (a *auditLog) search(text string, limit int) []AuditEntry {
+	text = strings.ToLower(text)
+	var found []AuditEntry
+	for i := len(a.entries) - 1; i >= 0 && len(found) < limit; i-- {
+		entry := a.entries[i]
+		if text == "" || strings.EqualFold(entry.Actor, text) || strings.EqualFold(entry.Target, text) || entry.Action == text {
+			found = append(found, entry)
+		}
+	}
+	for i, j := 0, len(found)-1; i < j; i, j = i+1, j-1 {
+		found[i], found[j] = found[j], found[i]
+	}
+	return found
+}
+
+// audit records an event caused by session, which may be nil for events
+// the server causes itself.
+func (g *Game) audit(session *Session, action, target, detail string) {
+	entry := AuditEntry{Time: g.clock.Now(), Action: action, Target: target, Detail: detail}
+	if session != nil {
+		entry.Actor, entry.IP = session.Name, sessionIP(session)
+	}
+	if err := g.auditLog.record(entry); err != nil {
+		storageLog.Error("Failed to write the audit log", "action", action, "err", err)
+	}
+}
+
+// String formats the entry as a line for /audit.
+func (e AuditEntry) String() string {
+	parts := []string{e.Time.Format("2006-01-02 15:04:05")}
+	if e.Actor != "" {
+		parts = append(parts, e.Actor)
+	}
+	parts = append(parts, e.Action)
+	if e.Target != "" {
+		parts = append(parts, e.Target)
+	}
+	line := strings.Join(parts, " ")
+	if e.Detail != "" {
+		line += ": " + e.Detail
+	}
+	if e.IP != "" {
+		line += fmt.Sprintf(" (%s)", e.IP)
+	}
+	return line
+}
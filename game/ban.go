@@ -0,0 +1,148 @@
+package game
+
+import (
+	"fmt"
+	"net"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Ban keeps a player name or an IP address out of the game.
+type Ban struct {
+	Name    string    `json:"name,omitempty"` // lowercased player name
+	IP      string    `json:"ip,omitempty"`
+	Reason  string    `json:"reason,omitempty"`
+	By      string    `json:"by"`
+	Expires time.Time `json:"expires,omitempty"` // zero for a permanent ban
+}
+
+// active reports whether the ban is still in force at now.
+func (b Ban) active(now time.Time) bool {
+	return b.Expires.IsZero() || now.Before(b.Expires)
+}
+
+// describe explains the ban to the player it keeps out.
+func (b Ban) describe() string {
+	message := "You are banned from this server"
+	if !b.Expires.IsZero() {
+		message += " until " + b.Expires.Format("2006-01-02 15:04")
+	}
+	if b.Reason != "" {
+		message += ": " + b.Reason
+	}
+	return message + "."
+}
+
+// findBan returns the active ban on name or ip, if any. Either may be empty.
+func (g *Game) findBan(name, ip string) *Ban {
+	now := g.clock.Now()
+	for i, ban := range g.bans {
+		if !ban.active(now) {
+			continue
+		}
+		if (name != "" && ban.Name == strings.ToLower(name)) || (ip != "" && ban.IP == ip) {
+			return &g.bans[i]
+		}
+	}
+	return nil
+}
+
+// addBan records a ban and drops the ones that have run out.
+func (g *Game) addBan(ban Ban) {
+	now := g.clock.Now()
+	bans := []Ban{ban}
+	for _, existing := range g.bans {
+		if existing.active(now) {
+			bans = append(bans, existing)
+		}
+	}
+	g.bans = bans
+	g.saveBans()
+}
+
+// removeBans lifts every ban on the player name or IP address and reports
+// how many there were.
+func (g *Game) removeBans(nameOrIP string) int {
+	var kept []Ban
+	for _, ban := range g.bans {
+		if ban.Name != strings.ToLower(nameOrIP) && ban.IP != nameOrIP {
+			kept = append(kept, ban)
+		}
+	}
+	removed := len(g.bans) - len(kept)
+	g.bans = kept
+	g.saveBans()
+	return removed
+}
+
+func (g *Game) loadBans() error {
+	if g.dataDir == "" {
+		return nil
+	}
+	if _, err := readJSONFile(g.bansPath(), &g.bans); err != nil {
+		return fmt.Errorf("reading bans: %w", err)
+	}
+	return nil
+}
+
+func (g *Game) saveBans() {
+	if g.dataDir == "" {
+		return
+	}
+	bans := g.bans
+	if bans == nil {
+		bans = []Ban{}
+	}
+	if err := writeJSONFile(g.bansPath(), bans); err != nil {
+		storageLog.Error("Failed to save bans", "err", err)
+	}
+}
+
+func (g *Game) bansPath() string {
+	return filepath.Join(g.dataDir, "bans.json")
+}
+
+// sessionIP returns the address a session connects from. Session IDs are
+// the remote address of the connection.
+func sessionIP(session *Session) string {
+	host, _, err := net.SplitHostPort(session.ID)
+	if err != nil {
+		return ""
+	}
+	return host
+}
+
+// bannableIP returns the address an IP ban on the account's player would
+// match. Staff are never locked out by an address ban, so staff sharing an
+// address with a troublemaker can still deal with them.
+func (a *Account) bannableIP(ip string) string {
+	if a.role() != RolePlayer {
+		return ""
+	}
+	return ip
+}
+
+// parseDuration parses lengths such as "30m", "12h" or "7d".
+func parseDuration(text string) (time.Duration, bool) {
+	if days, found := strings.CutSuffix(text, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, false
+		}
+		return time.Duration(n) * 24 * time.Hour, true
+	}
+	d, err := time.ParseDuration(text)
+	return d, err == nil && d > 0
+}
+
+// splitDuration takes an optional leading duration off the rest of a
+// command line, as in "/ban Bob 7d spamming".
+func splitDuration(rest string) (time.Duration, string) {
+	first, remainder, _ := strings.Cut(rest, " ")
+	if d, ok := parseDuration(first); ok {
+		return d, strings.TrimSpace(remainder)
+	}
+	return 0, rest
+}
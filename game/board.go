@@ -0,0 +1,88 @@
+package game
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const maxBoardPosts = 200 // older posts are dropped once a board holds this many
+
+const (
+	// PermissionBuild lets builders place and remove boards.
+	PermissionBuild Permission = "build"
+	// PermissionModerateBoards lets staff delete anyone's posts.
+	PermissionModerateBoards Permission = "moderate-boards"
+)
+
+// Board is a message board placed in a room.
+type Board struct {
+	Name            string     `json:"name"`
+	Room            string     `json:"room"`
+	ReadPermission  Permission `json:"read_permission,omitempty"`  // needed to read posts, empty for everyone
+	WritePermission Permission `json:"write_permission,omitempty"` // needed to post, empty for everyone
+	Posts           []Post     `json:"posts"`
+}
+
+// Post is a message on a board.
+type Post struct {
+	Author string    `json:"author"`
+	Title  string    `json:"title"`
+	Body   string    `json:"body"`
+	Posted time.Time `json:"posted"`
+}
+
+// loadBoards places the saved boards in their rooms. Without saved boards,
+// the lobby gets a general board.
+func (g *Game) loadBoards() error {
+	var boards []*Board
+	found := false
+	if g.dataDir != "" {
+		var err error
+		if found, err = readJSONFile(g.boardsPath(), &boards); err != nil {
+			return fmt.Errorf("reading boards: %w", err)
+		}
+	}
+	if !found {
+		boards = []*Board{{Name: "General board", Room: g.lobby.Name}}
+	}
+
+	for _, board := range boards {
+		room, exists := g.rooms[strings.ToLower(board.Room)]
+		if !exists {
+			storageLog.Warn("Skipping board in unknown room", "board", board.Name, "room", board.Room)
+			continue
+		}
+		room.Board = board
+	}
+	return nil
+}
+
+// saveBoards writes every board in the world to disk.
+func (g *Game) saveBoards() {
+	if g.dataDir == "" {
+		return
+	}
+	boards := []*Board{}
+	for _, room := range g.rooms {
+		if room.Board != nil {
+			boards = append(boards, room.Board)
+		}
+	}
+	if err := writeJSONFile(g.boardsPath(), boards); err != nil {
+		storageLog.Error("Failed to save boards", "err", err)
+	}
+}
+
+func (g *Game) boardsPath() string {
+	return filepath.Join(g.dataDir, "boards.json")
+}
+
+// addPost puts a post on the board, dropping the oldest if it is full.
+func (b *Board) addPost(post Post) {
+	b.Posts = append(b.Posts, post)
+	if len(b.Posts) > maxBoardPosts {
+		b.Posts = b.Posts[len(b.Posts)-maxBoardPosts:]
+	}
+}
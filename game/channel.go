@@ -0,0 +1,181 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+// channelHistorySize is how many messages a channel replays to players who
+// join it.
+const channelHistorySize = 20
+
+// PermissionModerateChannels lets staff moderate every channel, including
+// the built-in ones that have no owner.
+const PermissionModerateChannels Permission = "moderate-channels"
+
+// Channel is a named chat line players can talk on from any room.
+type Channel struct {
+	Name    string
+	Private bool   // only invited players can see and join it
+	Owner   string // lowercased name of the player who created it, empty for built-in channels
+	Members map[string]*Session
+	Invited map[string]bool // lowercased player names allowed to join a private channel
+	Muted   map[string]bool // lowercased player names who may not speak
+	history []string
+	actor   *actor // what is said on the channel goes through its actor
+}
+
+// defaultChannels are the public channels every new player starts on.
+var defaultChannels = []string{"gossip", "ooc", "newbie"}
+
+func (g *Game) newChannel(name string, private bool, owner string) *Channel {
+	return &Channel{
+		Name:    name,
+		Private: private,
+		Owner:   strings.ToLower(owner),
+		Members: make(map[string]*Session),
+		Invited: make(map[string]bool),
+		Muted:   make(map[string]bool),
+		actor:   newActor(g, "channel "+name),
+	}
+}
+
+// canSee reports whether the session may know the channel exists.
+func (c *Channel) canSee(session *Session) bool {
+	name := strings.ToLower(session.Name)
+	return !c.Private || c.Invited[name] || c.Owner == name || c.Members[session.ID] != nil
+}
+
+// canModerate reports whether the session may mute, kick and invite players.
+func (c *Channel) canModerate(session *Session) bool {
+	return session.hasPermission(PermissionModerateChannels) || (c.Owner != "" && c.Owner == strings.ToLower(session.Name))
+}
+
+// broadcast returns an event for every member announcing message.
+func (c *Channel) broadcast(message string) []OutputEvent {
+	line := fmt.Sprintf("[%s] %s", c.Name, message)
+	var messages []OutputEvent
+	for sessionID := range c.Members {
+		messages = append(messages, OutputEvent{SessionID: sessionID, Message: line})
+	}
+	return messages
+}
+
+// remember adds a line to the history replayed to players who join.
+func (c *Channel) remember(line string) {
+	c.history = append(c.history, line)
+	if len(c.history) > channelHistorySize {
+		c.history = c.history[len(c.history)-channelHistorySize:]
+	}
+}
+
+// joinChannel adds the session to the channel, remembers it on the account
+// and replays the channel history to the new member.
+func (g *Game) joinChannel(session *Session, channel *Channel) []OutputEvent {
+	messages := channel.broadcast(fmt.Sprintf("%s has joined the channel.", session.Name))
+	channel.Members[session.ID] = session
+	session.Account.addChannel(channel.Name)
+
+	reply := fmt.Sprintf("You joined %s.", channel.Name)
+	if len(channel.history) > 0 {
+		reply += "\nRecent messages:\n" + strings.Join(channel.history, "\n")
+	}
+	return append(messages, OutputEvent{SessionID: session.ID, Message: reply})
+}
+
+// leaveChannel removes the session from the channel for good, so the
+// account no longer rejoins it on login.
+func (g *Game) leaveChannel(session *Session, channel *Channel) []OutputEvent {
+	delete(channel.Members, session.ID)
+	session.Account.removeChannel(channel.Name)
+	messages := channel.broadcast(fmt.Sprintf("%s has left the channel.", session.Name))
+	g.removeIfAbandoned(channel)
+	return messages
+}
+
+// removeIfAbandoned deletes a player-created channel once nobody is on it.
+func (g *Game) removeIfAbandoned(channel *Channel) {
+	if len(channel.Members) == 0 && channel.Owner != "" {
+		delete(g.channels, channel.Name)
+		channel.actor.stop()
+	}
+}
+
+// rejoinChannels puts a player who just logged in back on their channels.
+func (g *Game) rejoinChannels(session *Session) {
+	if session.Account.Channels == nil {
+		session.Account.Channels = append([]string{}, defaultChannels...)
+	}
+	for _, name := range session.Account.Channels {
+		if channel, exists := g.channels[name]; exists && channel.canSee(session) {
+			channel.Members[session.ID] = session
+		}
+	}
+}
+
+// leaveAllChannels removes a session that is going away from every channel
+// without changing which channels the account rejoins.
+func (g *Game) leaveAllChannels(session *Session) {
+	for _, channel := range g.channels {
+		delete(channel.Members, session.ID)
+		g.removeIfAbandoned(channel)
+	}
+}
+
+// findJoinedChannel resolves a channel name or unique prefix among the
+// channels the session is on.
+func (g *Game) findJoinedChannel(session *Session, name string) *Channel {
+	name = strings.ToLower(name)
+	if channel, exists := g.channels[name]; exists && channel.Members[session.ID] != nil {
+		return channel
+	}
+	var match *Channel
+	for channelName, channel := range g.channels {
+		if channel.Members[session.ID] != nil && strings.HasPrefix(channelName, name) {
+			if match != nil {
+				return nil
+			}
+			match = channel
+		}
+	}
+	return match
+}
+
+// speakOnChannel sends a message from the session to everyone on channel.
+// The channel's actor passes it on, as players on it can be in any room.
+func (g *Game) speakOnChannel(session *Session, channel *Channel, message string) []OutputEvent {
+	if message == "" {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("What do you want to say on %s?", channel.Name)}}
+	}
+	if refusal := g.chatRefusal(session, message); refusal != nil {
+		return refusal
+	}
+	if channel.Muted[strings.ToLower(session.Name)] {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are muted on %s.", channel.Name)}}
+	}
+	message = fmt.Sprintf("%s: %s", session.Name, message)
+	channel.actor.post(job{run: func() []OutputEvent {
+		channel.remember(fmt.Sprintf("[%s] %s", channel.Name, message))
+		return from(session, channel.broadcast(message))
+	}})
+	return nil
+}
+
+func (a *Account) addChannel(name string) {
+	for _, existing := range a.Channels {
+		if existing == name {
+			return
+		}
+	}
+	a.Channels = append(a.Channels, name)
+}
+
+func (a *Account) removeChannel(name string) {
+	channels := []string{}
+	for _, existing := range a.Channels {
+		if existing != name {
+			channels = append(channels, existing)
+		}
+	}
+	a.Channels = channels
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Clock tells the game the time and runs functions later. Everything a
+// player can see the time in, such as mute expiry, idle times and the
+// dates on mail, goes through the game's clock, so simulations can control
+// it. Health checks and metrics always use real time.
+type Clock interface {
+	Now() time.Time
+	// AfterFunc arranges for f to be called once d has passed.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// A Timer is a call scheduled with Clock.AfterFunc.
+type Timer interface {
+	// Stop cancels the call, reporting whether it had not happened yet.
+	Stop() bool
+}
+
+// realClock is the clock on the wall.
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+func (realClock) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// VirtualClock is a Clock that only moves when it is advanced. Calls
+// scheduled with AfterFunc happen during Advance, on the goroutine that
+// advances the clock.
+type VirtualClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+}
+
+// NewVirtualClock returns a clock stopped at start.
+func NewVirtualClock(start time.Time) *VirtualClock {
+	return &VirtualClock{now: start}
+}
+
+func (c *VirtualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *VirtualClock) AfterFunc(d time.Duration, f func()) Timer {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &virtualTimer{clock: c, when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	// Timers due at the same time fire in the order they were set
+	sort.SliceStable(c.timers, func(i, j int) bool { return c.timers[i].when.Before(c.timers[j].when) })
+	return t
+}
+
+// Advance moves the clock forward by d, making the calls that fall due on
+// the way in order. The clock reads the time each call was due while it
+// runs. Advance(0) makes the calls that are already due.
+func (c *VirtualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	for len(c.timers) > 0 && !c.timers[0].when.After(end) {
+		t := c.timers[0]
+		c.timers = c.timers[1:]
+		if t.when.After(c.now) {
+			c.now = t.when
+		}
+		c.mu.Unlock()
+		t.f()
+		c.mu.Lock()
+	}
+	c.now = end
+	c.mu.Unlock()
+}
+
+type virtualTimer struct {
+	clock *VirtualClock
+	when  time.Time
+	f     func()
+}
+
+func (t *virtualTimer) Stop() bool {
+	c := t.clock
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, pending := range c.timers {
+		if pending == t {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
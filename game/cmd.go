@@ -1,3 +1,247 @@
 package game
 
-type command func(*Game, *Session, string, bool) []OutputEvent
\ No newline at end of file
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CommandHandler runs a command for a session with the arguments parsed
+// according to the command's ArgSpecs.
+type CommandHandler func(g *Game, session *Session, args *Args) []OutputEvent
+
+// LoginState is the stage of the login flow a session is in.
+type LoginState int
+
+const (
+	StateNaming   LoginState = iota // waiting for the player to choose a name
+	StatePlaying                    // logged in and in the world
+	StateEditing                    // writing multi-line text in the line editor
+	StatePassword                   // waiting for the password of the account the player named
+	StateChecking                   // waiting for the password given to be checked
+)
+
+// Permission names a capability a command can require.
+type Permission string
+
+// Command describes a command that can be added with RegisterCommand.
+type Command struct {
+	Name       string
+	Aliases    []string
+	Short      string       // one-line summary shown in /help
+	Args       []ArgSpec    // arguments the command takes, checked before the handler runs
+	Usage      string       // e.g. "/whisper <username> <message>", generated from Args if empty
+	Help       string       // longer description shown by /help <command>
+	Category   string       // heading the command is listed under in /help
+	Permission Permission   // required to run the command, empty for everyone; see WithRolePermissions
+	States     []LoginState // states the command is available in, defaults to StatePlaying
+	Local      bool         // only touches the player and their room, so it runs alongside other rooms
+	Handler    CommandHandler
+}
+
+// availableIn reports whether the command may be run in the given state.
+func (c *Command) availableIn(state LoginState) bool {
+	if len(c.States) == 0 {
+		return state == StatePlaying
+	}
+	for _, s := range c.States {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
+// helpText renders the detailed help shown by /help <command>.
+func (c *Command) helpText() string {
+	lines := []string{c.Short, "Usage: " + c.Usage}
+	if len(c.Aliases) > 0 {
+		lines = append(lines, "Aliases: "+strings.Join(c.Aliases, ", "))
+	}
+	if c.Help != "" {
+		lines = append(lines, c.Help)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RegisterCommand makes a command available to players. It fails if the
+// command has no name or handler, or if its name or one of its aliases is
+// already taken.
+func (g *Game) RegisterCommand(cmd Command) error {
+	if cmd.Name == "" {
+		return errors.New("command has no name")
+	}
+	if cmd.Handler == nil {
+		return fmt.Errorf("command %s has no handler", cmd.Name)
+	}
+	if cmd.Usage == "" {
+		cmd.Usage = usageFromArgs(cmd.Name, cmd.Args)
+	}
+	if cmd.Category == "" {
+		cmd.Category = "General"
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		if _, exists := g.commands[name]; exists {
+			return fmt.Errorf("command name %s is already registered", name)
+		}
+	}
+	c := &cmd
+	for _, name := range append([]string{cmd.Name}, cmd.Aliases...) {
+		g.commands[name] = c
+	}
+	return nil
+}
+
+// commandList returns every registered command once, sorted by name.
+func (g *Game) commandList() []*Command {
+	seen := make(map[*Command]bool)
+	var list []*Command
+	for _, c := range g.commands {
+		if !seen[c] {
+			seen[c] = true
+			list = append(list, c)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// splitCommandLine separates the command name from its parameters. A
+// leading punctuation alias such as "'" does not need a space after it.
+func (g *Game) splitCommandLine(input string) (string, string) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", ""
+	}
+	if first := input[:1]; !isWordByte(first[0]) {
+		if _, exists := g.commands[first]; exists {
+			return first, strings.TrimSpace(input[1:])
+		}
+	}
+	name, params, _ := strings.Cut(input, " ")
+	return name, strings.TrimSpace(params)
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// findCommand resolves a name typed by the player to a command the session
+// can use. Exact names and aliases win; otherwise the name may be an
+// abbreviation of exactly one command. If nothing matches, the returned
+// error message explains why and suggests alternatives.
+func (g *Game) findCommand(session *Session, name string) (*Command, string) {
+	name = strings.ToLower(name)
+	if name == "" {
+		return nil, "Type a command after the /, or /help for a list of commands."
+	}
+	if command, exists := g.commands[name]; exists && session.canUse(command) {
+		return command, ""
+	}
+
+	var matches []string
+	for _, command := range g.commandList() {
+		if session.canUse(command) && strings.HasPrefix(command.Name, name) {
+			matches = append(matches, command.Name)
+		}
+	}
+	switch {
+	case len(matches) == 1:
+		return g.commands[matches[0]], ""
+	case len(matches) > 1:
+		return nil, fmt.Sprintf("Ambiguous command: %s. Did you mean %s?", name, joinOr(prefixAll("/", matches)))
+	}
+
+	message := fmt.Sprintf("Unknown command: %s", name)
+	if suggestions := g.suggestCommands(session, name); len(suggestions) > 0 {
+		message += fmt.Sprintf(". Did you mean %s?", joinOr(prefixAll("/", suggestions)))
+	}
+	return nil, message
+}
+
+// suggestCommands returns the usable commands whose names are within a
+// couple of typos of name.
+func (g *Game) suggestCommands(session *Session, name string) []string {
+	maxDistance := 2
+	if len(name) <= 3 {
+		maxDistance = 1
+	}
+	var suggestions []string
+	for _, command := range g.commandList() {
+		if session.canUse(command) && editDistance(name, command.Name) <= maxDistance {
+			suggestions = append(suggestions, command.Name)
+		}
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func prefixAll(prefix string, words []string) []string {
+	prefixed := make([]string, len(words))
+	for i, word := range words {
+		prefixed[i] = prefix + word
+	}
+	return prefixed
+}
+
+// joinOr joins words as "a, b or c".
+func joinOr(words []string) string {
+	if len(words) <= 1 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " or " + words[len(words)-1]
+}
+
+// usageError tells the player what was wrong with how they called a command
+// and how to call it instead.
+func usageError(session *Session, command *Command, problem string) []OutputEvent {
+	return []OutputEvent{{
+		SessionID: session.ID,
+		Message:   fmt.Sprintf("%s\nUsage: %s", problem, command.Usage),
+	}}
+}
+
+// canUse reports whether the session may run the command right now.
+func (s *Session) canUse(c *Command) bool {
+	return c.availableIn(s.State) && s.hasPermission(c.Permission)
+}
+
+// loggedIn reports whether the session has finished logging in.
+func (s *Session) loggedIn() bool {
+	return s.State != StateNaming && s.State != StatePassword && s.State != StateChecking
+}
+
+func (s *Session) hasPermission(p Permission) bool {
+	return p == "" || s.Permissions[p]
+}
+
+// isStaff reports whether the session's account has a role above player.
+// Permissions granted to every player do not make anyone staff.
+func (s *Session) isStaff() bool {
+	return s.Account != nil && s.Account.role() != RolePlayer
+}
@@ -0,0 +1,41 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var afkCommand = Command{
+	Name:     "afk",
+	Short:    "Mark yourself as away from the keyboard, or back.",
+	Args:     []ArgSpec{{Name: "message", Kind: ArgRest, Optional: true}},
+	Help:     "Players who whisper to you see your message. Doing anything else marks you as back.",
+	Category: "Settings",
+	Local:    true,
+	Handler:  handleAfk,
+}
+
+func handleAfk(_ *Game, session *Session, args *Args) []OutputEvent {
+	if session.afk {
+		session.afk, session.afkMessage = false, ""
+		return []OutputEvent{{SessionID: session.ID, Message: "You are no longer AFK."}}
+	}
+	session.afk, session.afkMessage = true, args.String("message")
+	return []OutputEvent{{SessionID: session.ID, Message: "You are now AFK."}}
+}
+
+// clearAfk marks a player who is doing something as back.
+func clearAfk(session *Session) []OutputEvent {
+	if !session.afk {
+		return nil
+	}
+	session.afk, session.afkMessage = false, ""
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Welcome back, %s. You are no longer AFK.", session.Name)}}
+}
+
+// isAfkCommand reports whether input is the /afk command itself, which must
+// not count as coming back.
+func isAfkCommand(input string) bool {
+	name, _, _ := strings.Cut(strings.TrimPrefix(input, "/"), " ")
+	return strings.EqualFold(name, "afk")
+}
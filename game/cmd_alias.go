@@ -0,0 +1,155 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxAliasDepth    = 5  // how deeply aliases may expand into other aliases
+	maxAliasCommands = 20 // how many commands a single line may expand into
+	maxAliases       = 50 // how many aliases a player may define
+)
+
+var aliasCommand = Command{
+	Name:     "alias",
+	Short:    "Define a shortcut for one or more commands.",
+	Args:     []ArgSpec{{Name: "name", Optional: true}, {Name: "expansion", Kind: ArgRest, Optional: true}},
+	Help:     "Separate commands with ;. $1 to $9 are replaced by the alias arguments and $* by all of them. If the expansion uses none, the arguments are appended.\nExample: /alias gw say hi $1;whisper $1 welcome!",
+	Category: "Settings",
+	Handler:  handleAlias,
+}
+
+var unaliasCommand = Command{
+	Name:     "unalias",
+	Short:    "Remove an alias.",
+	Args:     []ArgSpec{{Name: "name"}},
+	Category: "Settings",
+	Handler:  handleUnalias,
+}
+
+func handleAlias(g *Game, session *Session, args *Args) []OutputEvent {
+	aliases := session.Account.Aliases
+	name := strings.ToLower(strings.TrimPrefix(args.String("name"), "/"))
+	expansion := args.String("expansion")
+
+	if name == "" {
+		if len(aliases) == 0 {
+			return []OutputEvent{{SessionID: session.ID, Message: "You have no aliases."}}
+		}
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		lines := []string{"Your aliases:"}
+		for _, name := range names {
+			lines = append(lines, fmt.Sprintf("  %s = %s", name, aliases[name]))
+		}
+		return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+	}
+
+	if expansion == "" {
+		if current, exists := aliases[name]; exists {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s = %s", name, current)}}
+		}
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You have no alias named %s.", name)}}
+	}
+
+	if name == "alias" || name == "unalias" {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You cannot redefine /%s.", name)}}
+	}
+	if _, exists := aliases[name]; !exists && len(aliases) >= maxAliases {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You cannot have more than %d aliases.", maxAliases)}}
+	}
+
+	aliases[name] = expansion
+	if err := g.accounts.save(session.Account); err != nil {
+		withSession(storageLog, session).Error("Failed to save aliases", "err", err)
+		return []OutputEvent{{SessionID: session.ID, Message: "Your alias is set, but could not be saved."}}
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Alias set: %s = %s", name, expansion)}}
+}
+
+func handleUnalias(g *Game, session *Session, args *Args) []OutputEvent {
+	name := strings.ToLower(strings.TrimPrefix(args.String("name"), "/"))
+	if _, exists := session.Account.Aliases[name]; !exists {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You have no alias named %s.", name)}}
+	}
+
+	delete(session.Account.Aliases, name)
+	if err := g.accounts.save(session.Account); err != nil {
+		withSession(storageLog, session).Error("Failed to save aliases", "err", err)
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Alias removed: %s", name)}}
+}
+
+// expandAliases turns a command line into the command lines it stands for,
+// following aliases that expand into other aliases up to maxAliasDepth. If
+// the line cannot be expanded, the returned message explains why.
+func (g *Game) expandAliases(session *Session, line string, depth int) ([]string, string) {
+	if session.Account == nil {
+		return []string{line}, ""
+	}
+	name, params := g.splitCommandLine(line)
+	expansion, exists := session.Account.Aliases[strings.ToLower(name)]
+	if !exists {
+		return []string{line}, ""
+	}
+	if depth >= maxAliasDepth {
+		return nil, fmt.Sprintf("Alias %s nests too deeply.", name)
+	}
+
+	var lines []string
+	for _, part := range strings.Split(substituteAliasArgs(expansion, params), ";") {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "/")
+		if part == "" {
+			continue
+		}
+		expanded, problem := g.expandAliases(session, part, depth+1)
+		if problem != "" {
+			return nil, problem
+		}
+		lines = append(lines, expanded...)
+		if len(lines) > maxAliasCommands {
+			return nil, fmt.Sprintf("Alias %s expands to more than %d commands.", name, maxAliasCommands)
+		}
+	}
+	return lines, ""
+}
+
+// substituteAliasArgs replaces $1 to $9 and $* in expansion. When expansion
+// refers to no arguments at all, they are appended instead.
+func substituteAliasArgs(expansion, params string) string {
+	args := strings.Fields(params)
+	used := false
+
+	var b strings.Builder
+	for i := 0; i < len(expansion); i++ {
+		if expansion[i] == '$' && i+1 < len(expansion) {
+			next := expansion[i+1]
+			if next == '*' {
+				b.WriteString(params)
+				used = true
+				i++
+				continue
+			}
+			if n, err := strconv.Atoi(string(next)); err == nil && n > 0 {
+				if n <= len(args) {
+					b.WriteString(args[n-1])
+				}
+				used = true
+				i++
+				continue
+			}
+		}
+		b.WriteByte(expansion[i])
+	}
+
+	if !used && params != "" {
+		b.WriteString(" " + params)
+	}
+	return b.String()
+}
@@ -0,0 +1,34 @@
+package game
+
+import (
+	"strings"
+)
+
+// auditLines is how many entries /audit shows.
+const auditLines = 20
+
+// PermissionAudit lets staff read the audit log.
+const PermissionAudit Permission = "audit"
+
+var auditCommand = Command{
+	Name:  "audit",
+	Short: "Show recent administrative and security events.",
+	Args:  []ArgSpec{{Name: "player or action", Optional: true}},
+	Help: "Shows the latest events, or only those by or about a player, or of one\n" +
+		"kind such as login, login-refused, command or role.",
+	Category:   "Admin",
+	Permission: PermissionAudit,
+	Handler:    handleAudit,
+}
+
+func handleAudit(g *Game, session *Session, args *Args) []OutputEvent {
+	entries := g.auditLog.search(args.String("player or action"), auditLines)
+	if len(entries) == 0 {
+		return []OutputEvent{{SessionID: session.ID, Message: "No matching events."}}
+	}
+	lines := make([]string, len(entries))
+	for i, entry := range entries {
+		lines[i] = entry.String()
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+}
@@ -0,0 +1,112 @@
+package game
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+)
+
+var banCommand = Command{
+	Name:  "ban",
+	Short: "Keep a player or an IP address out of the game.",
+	Args:  []ArgSpec{{Name: "player"}, {Name: "duration and reason", Kind: ArgRest, Optional: true}},
+	Usage: "/ban <player|ip> [<duration>] [<reason>]",
+	Help: "Banning a player also bans the address they last played from. Without a\n" +
+		"duration such as 30m, 12h or 7d, the ban lasts until lifted with /unban.",
+	Category:   "Admin",
+	Permission: PermissionBan,
+	Handler:    handleBan,
+}
+
+var unbanCommand = Command{
+	Name:       "unban",
+	Short:      "Lift the bans on a player or an IP address.",
+	Args:       []ArgSpec{{Name: "player"}},
+	Usage:      "/unban <player|ip>",
+	Category:   "Admin",
+	Permission: PermissionBan,
+	Handler:    handleUnban,
+}
+
+var bansCommand = Command{
+	Name:       "bans",
+	Short:      "List the bans in force.",
+	Category:   "Admin",
+	Permission: PermissionBan,
+	Handler:    handleBans,
+}
+
+func handleBan(g *Game, session *Session, args *Args) []OutputEvent {
+	d, reason := splitDuration(args.String("duration and reason"))
+	ban := Ban{Reason: reason, By: session.Name}
+	if d > 0 {
+		ban.Expires = g.clock.Now().Add(d)
+	}
+
+	name := args.String("player")
+	if ip := net.ParseIP(name); ip != nil {
+		ban.IP = ip.String()
+		g.addBan(ban)
+		return append(
+			[]OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You banned %s.", ban.IP)}},
+			g.disconnectBanned(ban)...,
+		)
+	}
+
+	account, refusal := g.staffTargetAccount(session, name)
+	if refusal != nil {
+		return refusal
+	}
+	ban.Name, ban.IP = strings.ToLower(account.Name), account.LastIP
+	g.addBan(ban)
+	reply := fmt.Sprintf("You banned %s.", account.Name)
+	if ban.IP != "" {
+		reply = fmt.Sprintf("You banned %s and %s.", account.Name, ban.IP)
+	}
+	return append([]OutputEvent{{SessionID: session.ID, Message: reply}}, g.disconnectBanned(ban)...)
+}
+
+// disconnectBanned throws out everyone the ban applies to.
+func (g *Game) disconnectBanned(ban Ban) []OutputEvent {
+	var messages []OutputEvent
+	for _, s := range g.sessions {
+		if s.loggedIn() && (strings.ToLower(s.Name) == ban.Name || (ban.IP != "" && s.Account.bannableIP(sessionIP(s)) == ban.IP)) {
+			messages = append(messages, OutputEvent{SessionID: s.ID, Message: ban.describe(), Quit: true})
+			messages = g.endSession(s, messages)
+		}
+	}
+	return messages
+}
+
+func handleUnban(g *Game, session *Session, args *Args) []OutputEvent {
+	name := args.String("player")
+	if g.removeBans(name) == 0 {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is not banned.", name)}}
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You lifted the ban on %s.", name)}}
+}
+
+func handleBans(g *Game, session *Session, _ *Args) []OutputEvent {
+	now := g.clock.Now()
+	var lines []string
+	for _, ban := range g.bans {
+		if !ban.active(now) {
+			continue
+		}
+		subject := strings.Trim(strings.Join([]string{ban.Name, ban.IP}, " "), " ")
+		line := fmt.Sprintf("%s, by %s", subject, ban.By)
+		if !ban.Expires.IsZero() {
+			line += ", until " + ban.Expires.Format("2006-01-02 15:04")
+		}
+		if ban.Reason != "" {
+			line += ": " + ban.Reason
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		return []OutputEvent{{SessionID: session.ID, Message: "Nobody is banned."}}
+	}
+	sort.Strings(lines)
+	return []OutputEvent{{SessionID: session.ID, Message: "Bans:\n" + strings.Join(lines, "\n")}}
+}
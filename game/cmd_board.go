@@ -0,0 +1,148 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var boardCommand = Command{
+	Name:  "board",
+	Short: "Read and post on the message board in this room.",
+	Args:  []ArgSpec{{Name: "action", Optional: true}, {Name: "arguments", Kind: ArgRest, Optional: true}},
+	Usage: "/board [list | read <number> | post <title> | delete <number> | place <name> [<read-permission>] [<write-permission>] | remove]",
+	Help: "/board post opens an editor for the message body. You can delete your own posts.\n" +
+		"Builders can place a board in the room they are in, optionally restricting who may read or post on it.",
+	Category: "Communication",
+	Handler:  handleBoard,
+}
+
+func handleBoard(g *Game, session *Session, args *Args) []OutputEvent {
+	command := g.commands["board"]
+	action := strings.ToLower(args.String("action"))
+	var specs []ArgSpec
+	switch action {
+	case "", "list", "remove":
+	case "read", "delete":
+		specs = []ArgSpec{{Name: "number", Kind: ArgNumber}}
+	case "post":
+		specs = []ArgSpec{{Name: "title", Kind: ArgRest}}
+	case "place":
+		specs = []ArgSpec{{Name: "name"}, {Name: "read-permission", Optional: true}, {Name: "write-permission", Optional: true}}
+	default:
+		return usageError(session, command, fmt.Sprintf("Unknown action: %s.", action))
+	}
+	actionArgs, err := parseArgs(specs, args.String("arguments"))
+	if err != nil {
+		return usageError(session, command, err.Error())
+	}
+
+	if action == "place" || action == "remove" {
+		return buildBoard(g, session, action, actionArgs)
+	}
+
+	board := session.Room.Board
+	if board == nil {
+		return []OutputEvent{{SessionID: session.ID, Message: "There is no board here."}}
+	}
+	if !session.hasPermission(board.ReadPermission) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are not allowed to read the %s.", board.Name)}}
+	}
+
+	switch action {
+	case "", "list":
+		return listBoard(session, board)
+	case "post":
+		if !session.hasPermission(board.WritePermission) {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are not allowed to post on the %s.", board.Name)}}
+		}
+		if refusal := g.mutedRefusal(session); refusal != nil {
+			return refusal
+		}
+		title := actionArgs.String("title")
+		return g.startEditor(session, fmt.Sprintf("Posting on the %s: %s", board.Name, title), func(g *Game, session *Session, body string) []OutputEvent {
+			if refusal := g.chatRefusal(session, body); refusal != nil {
+				return refusal
+			}
+			board.addPost(Post{Author: session.Name, Title: title, Body: body, Posted: g.clock.Now()})
+			g.saveBoards()
+			return append(
+				[]OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You post \"%s\" on the %s.", title, board.Name)}},
+				g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s posts a message on the %s.", session.Name, board.Name), session.ID)...,
+			)
+		})
+	}
+
+	n := actionArgs.Int("number")
+	if n < 1 || n > len(board.Posts) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is no post %d.", n)}}
+	}
+	post := board.Posts[n-1]
+	if action == "delete" {
+		if !strings.EqualFold(post.Author, session.Name) && !session.hasPermission(PermissionModerateBoards) {
+			return []OutputEvent{{SessionID: session.ID, Message: "You can only delete your own posts."}}
+		}
+		board.Posts = append(board.Posts[:n-1], board.Posts[n:]...)
+		g.saveBoards()
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Post %d deleted.", n)}}
+	}
+	return []OutputEvent{{
+		SessionID: session.ID,
+		Message: fmt.Sprintf("%d. %s\nBy %s on %s\n\n%s",
+			n, post.Title, post.Author, post.Posted.Format("2006-01-02 15:04"), post.Body),
+	}}
+}
+
+func listBoard(session *Session, board *Board) []OutputEvent {
+	if len(board.Posts) == 0 {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("The %s is empty.", board.Name)}}
+	}
+	lines := []string{fmt.Sprintf("The %s:", board.Name)}
+	for i, post := range board.Posts {
+		lines = append(lines, fmt.Sprintf("%3d. %-20s %s  %s", i+1, post.Author, post.Posted.Format("2006-01-02"), post.Title))
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+}
+
+func buildBoard(g *Game, session *Session, action string, args *Args) []OutputEvent {
+	if !session.hasPermission(PermissionBuild) {
+		return []OutputEvent{{SessionID: session.ID, Message: "Only builders can place and remove boards."}}
+	}
+	room := session.Room
+	if action == "remove" {
+		if room.Board == nil {
+			return []OutputEvent{{SessionID: session.ID, Message: "There is no board here."}}
+		}
+		name := room.Board.Name
+		room.Board = nil
+		g.saveBoards()
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You remove the %s.", name)}}
+	}
+
+	if room.Board != nil {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is already a board here: the %s.", room.Board.Name)}}
+	}
+	read, write := Permission(args.String("read-permission")), Permission(args.String("write-permission"))
+	known := g.permissionsOf(RoleOwner)
+	for _, p := range []Permission{read, write} {
+		if p != "" && !known[p] {
+			names := make([]string, 0, len(known))
+			for name := range known {
+				names = append(names, string(name))
+			}
+			sort.Strings(names)
+			return []OutputEvent{{
+				SessionID: session.ID,
+				Message:   fmt.Sprintf("Unknown permission: %s. Known permissions: %s.", p, strings.Join(names, ", ")),
+			}}
+		}
+	}
+	room.Board = &Board{
+		Name:            args.String("name"),
+		Room:            room.Name,
+		ReadPermission:  read,
+		WritePermission: write,
+	}
+	g.saveBoards()
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You place the %s here.", room.Board.Name)}}
+}
@@ -0,0 +1,19 @@
+package game
+
+import (
+	"fmt"
+)
+
+var broadcastCommand = Command{
+	Name:       "broadcast",
+	Short:      "Send a message to everyone connected.",
+	Args:       []ArgSpec{{Name: "message", Kind: ArgRest}},
+	Category:   "Admin",
+	Permission: PermissionBroadcast,
+	Handler:    handleBroadcast,
+}
+
+func handleBroadcast(_ *Game, session *Session, args *Args) []OutputEvent {
+	// An event without a session ID goes to every session.
+	return []OutputEvent{{Message: fmt.Sprintf("[Broadcast] %s: %s", session.Name, args.String("message"))}}
+}
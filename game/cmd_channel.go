@@ -0,0 +1,158 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var channelCommand = Command{
+	Name:    "channel",
+	Aliases: []string{"ch"},
+	Short:   "Talk with players anywhere in the game.",
+	Args: []ArgSpec{
+		{Name: "action"},
+		{Name: "channel", Optional: true},
+		{Name: "player", Optional: true},
+	},
+	Usage: "/channel list|join|leave|who|create|invite|mute|unmute|kick [<channel>] [<player>]",
+	Help: "Speak on a channel you are on with /<channel> <message>; any unique prefix of the channel name works, e.g. /gos hello.\n" +
+		"Channels you create are private: only players you invite can see and join them, and they close when everyone has left.",
+	Category: "Communication",
+	Handler:  handleChannel,
+}
+
+func handleChannel(g *Game, session *Session, args *Args) []OutputEvent {
+	action := strings.ToLower(args.String("action"))
+	if action == "list" {
+		return listChannels(g, session)
+	}
+
+	name := strings.ToLower(args.String("channel"))
+	if name == "" {
+		return usageError(session, g.commands["channel"], "Missing <channel>.")
+	}
+	if action == "create" {
+		return createChannel(g, session, name)
+	}
+
+	channel, exists := g.channels[name]
+	if !exists || !channel.canSee(session) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is no channel named %s.", name)}}
+	}
+	_, member := channel.Members[session.ID]
+
+	var messages []OutputEvent
+	switch action {
+	case "join":
+		if member {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are already on %s.", channel.Name)}}
+		}
+		messages = g.joinChannel(session, channel)
+	case "leave":
+		if !member {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are not on %s.", channel.Name)}}
+		}
+		messages = append(g.leaveChannel(session, channel), OutputEvent{SessionID: session.ID, Message: fmt.Sprintf("You left %s.", channel.Name)})
+	case "who":
+		names := []string{}
+		for _, s := range channel.Members {
+			names = append(names, s.Name)
+		}
+		sort.Strings(names)
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("On %s: %s", channel.Name, strings.Join(names, ", "))}}
+	case "invite", "mute", "unmute", "kick":
+		return moderateChannel(g, session, channel, action, args.String("player"))
+	default:
+		return usageError(session, g.commands["channel"], fmt.Sprintf("Unknown action: %s.", action))
+	}
+
+	if err := g.accounts.save(session.Account); err != nil {
+		withSession(storageLog, session).Error("Failed to save channels", "err", err)
+	}
+	return messages
+}
+
+func listChannels(g *Game, session *Session) []OutputEvent {
+	names := []string{}
+	for name, channel := range g.channels {
+		if channel.canSee(session) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	lines := []string{"Channels (* means you are on it):"}
+	for _, name := range names {
+		channel := g.channels[name]
+		marker := " "
+		if channel.Members[session.ID] != nil {
+			marker = "*"
+		}
+		kind := "public"
+		if channel.Private {
+			kind = "private"
+		}
+		lines = append(lines, fmt.Sprintf(" %s %s (%s, %d online)", marker, name, kind, len(channel.Members)))
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+}
+
+func createChannel(g *Game, session *Session, name string) []OutputEvent {
+	if !validName(name) {
+		return []OutputEvent{{SessionID: session.ID, Message: "Channel names must be 2 to 20 letters or digits."}}
+	}
+	if _, exists := g.channels[name]; exists {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is already a channel named %s.", name)}}
+	}
+	if _, exists := g.commands[name]; exists {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is the name of a command.", name)}}
+	}
+
+	channel := g.newChannel(name, true, session.Name)
+	g.channels[name] = channel
+	messages := g.joinChannel(session, channel)
+	if err := g.accounts.save(session.Account); err != nil {
+		withSession(storageLog, session).Error("Failed to save channels", "err", err)
+	}
+	return append([]OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You created the private channel %s. Use /channel invite %s <player> to let others join.", name, name)}}, messages...)
+}
+
+func moderateChannel(g *Game, session *Session, channel *Channel, action, playerName string) []OutputEvent {
+	if !channel.canModerate(session) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You cannot moderate %s.", channel.Name)}}
+	}
+	if playerName == "" {
+		return usageError(session, g.commands["channel"], "Missing <player>.")
+	}
+	key := strings.ToLower(playerName)
+
+	switch action {
+	case "invite":
+		channel.Invited[key] = true
+		messages := []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You invited %s to %s.", playerName, channel.Name)}}
+		if target, online := g.usernames[key]; online {
+			messages = append(messages, OutputEvent{SessionID: target.ID, Message: fmt.Sprintf("%s invited you to the channel %s. Type /channel join %s to join.", session.Name, channel.Name, channel.Name)})
+		}
+		return messages
+	case "mute":
+		channel.Muted[key] = true
+		return channel.broadcast(fmt.Sprintf("%s was muted by %s.", playerName, session.Name))
+	case "unmute":
+		delete(channel.Muted, key)
+		return channel.broadcast(fmt.Sprintf("%s was unmuted by %s.", playerName, session.Name))
+	default:
+		target, online := g.usernames[key]
+		if !online || channel.Members[target.ID] == nil {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is not on %s.", playerName, channel.Name)}}
+		}
+		delete(channel.Invited, key)
+		messages := channel.broadcast(fmt.Sprintf("%s was kicked from the channel by %s.", target.Name, session.Name))
+		delete(channel.Members, target.ID)
+		target.Account.removeChannel(channel.Name)
+		if err := g.accounts.save(target.Account); err != nil {
+			withSession(storageLog, target).Error("Failed to save channels", "err", err)
+		}
+		return messages
+	}
+}
@@ -0,0 +1,23 @@
+package game
+
+import (
+	"fmt"
+)
+
+var emoteCommand = Command{
+	Name:     "emote",
+	Aliases:  []string{":"},
+	Short:    "Describe an action to everyone in the room.",
+	Args:     []ArgSpec{{Name: "action", Kind: ArgRest}},
+	Help:     "Example: /emote waves happily. shows \"Alice waves happily.\"",
+	Category: "Communication",
+	Local:    true,
+	Handler:  handleEmote,
+}
+
+func handleEmote(g *Game, session *Session, args *Args) []OutputEvent {
+	if refusal := g.chatRefusal(session, "/emote "+args.String("action")); refusal != nil {
+		return refusal
+	}
+	return from(session, g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s %s", session.Name, args.String("action"))))
+}
@@ -0,0 +1,52 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var fingerCommand = Command{
+	Name:     "finger",
+	Aliases:  []string{"whois"},
+	Short:    "Show information about a player, online or not.",
+	Args:     []ArgSpec{{Name: "player"}},
+	Category: "Information",
+	Handler:  handleFinger,
+}
+
+func handleFinger(g *Game, session *Session, args *Args) []OutputEvent {
+	name := args.String("player")
+	if !validName(name) || !g.accounts.exists(name) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("User '%s' not found.", name)}}
+	}
+	account, err := g.accounts.load(name)
+	if err != nil {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Could not look up %s.", name)}}
+	}
+
+	lines := []string{account.Name}
+	if account.Title != "" {
+		lines[0] += " " + account.Title
+	}
+	lines = append(lines, account.class())
+
+	if player, online := g.usernames[strings.ToLower(account.Name)]; online {
+		status := fmt.Sprintf("Online in %s, idle %s", player.Room.Name, formatIdle(g.clock.Now().Sub(player.lastInput)))
+		if player.afk {
+			status += ", AFK"
+			if player.afkMessage != "" {
+				status += ": " + player.afkMessage
+			}
+		}
+		lines = append(lines, status)
+		if player.group != nil {
+			lines = append(lines, fmt.Sprintf("In %s's group", player.group.Leader.Name))
+		}
+	} else {
+		lines = append(lines, "Offline")
+	}
+	if !account.LastLogin.IsZero() {
+		lines = append(lines, "Last login: "+account.LastLogin.Format("2006-01-02 15:04"))
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+}
@@ -0,0 +1,62 @@
+package game
+
+import (
+	"fmt"
+)
+
+var followCommand = Command{
+	Name:     "follow",
+	Short:    "Follow another player when they move, or see who you follow.",
+	Args:     []ArgSpec{{Name: "player", Kind: ArgTarget, Optional: true}},
+	Category: "World",
+	Local:    true,
+	Handler:  handleFollow,
+}
+
+var unfollowCommand = Command{
+	Name:     "unfollow",
+	Short:    "Stop following anyone.",
+	Category: "World",
+	Local:    true,
+	Handler:  handleUnfollow,
+}
+
+func handleFollow(g *Game, session *Session, args *Args) []OutputEvent {
+	if !args.Has("player") {
+		if session.following == nil {
+			return []OutputEvent{{SessionID: session.ID, Message: "You are not following anyone."}}
+		}
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are following %s.", session.following.Name)}}
+	}
+
+	if args.Target("player").All {
+		return []OutputEvent{{SessionID: session.ID, Message: "You can only follow one player at a time."}}
+	}
+	target := g.findInRoom(session.Room, args.Target("player"))
+	switch {
+	case target == nil:
+		return []OutputEvent{{SessionID: session.ID, Message: "You don't see them here."}}
+	case target == session:
+		return handleUnfollow(g, session, args)
+	case session.following == target:
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are already following %s.", target.Name)}}
+	}
+
+	session.following = target
+	return []OutputEvent{
+		{SessionID: session.ID, Message: fmt.Sprintf("You start following %s.", target.Name)},
+		{SessionID: target.ID, Message: fmt.Sprintf("%s starts following you.", session.Name), From: session.Name},
+	}
+}
+
+func handleUnfollow(_ *Game, session *Session, _ *Args) []OutputEvent {
+	if session.following == nil {
+		return []OutputEvent{{SessionID: session.ID, Message: "You are not following anyone."}}
+	}
+	leader := session.following
+	session.following = nil
+	return []OutputEvent{
+		{SessionID: session.ID, Message: fmt.Sprintf("You stop following %s.", leader.Name)},
+		{SessionID: leader.ID, Message: fmt.Sprintf("%s stops following you.", session.Name)},
+	}
+}
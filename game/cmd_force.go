@@ -0,0 +1,34 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var forceCommand = Command{
+	Name:       "force",
+	Short:      "Make a player run a command.",
+	Args:       []ArgSpec{{Name: "player"}, {Name: "command", Kind: ArgRest}},
+	Help:       "Example: /force Bob /say I am sorry",
+	Category:   "Admin",
+	Permission: PermissionForce,
+	Handler:    handleForce,
+}
+
+func handleForce(g *Game, session *Session, args *Args) []OutputEvent {
+	target, refusal := g.staffTarget(session, args.String("player"))
+	if refusal != nil {
+		return refusal
+	}
+	command := strings.TrimPrefix(args.String("command"), "/")
+	messages := []OutputEvent{
+		{SessionID: session.ID, Message: fmt.Sprintf("You force %s to: /%s", target.Name, command)},
+		{SessionID: target.ID, Message: fmt.Sprintf("%s forces you to: /%s", session.Name, command)},
+	}
+	output, quit := g.handleCommand(target, command)
+	messages = append(messages, output...)
+	if quit {
+		messages = g.endSession(target, messages)
+	}
+	return messages
+}
@@ -0,0 +1,53 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var goCommand = Command{
+	Name:     "go",
+	Short:    "Walk through an exit.",
+	Args:     []ArgSpec{{Name: "direction"}},
+	Help:     "You can also type the direction on its own, e.g. /north or /n.",
+	Category: "World",
+	Local:    true,
+	Handler:  handleGo,
+}
+
+// directionCommands returns a command for walking in each direction, with
+// its first letter as an alias.
+func directionCommands() []Command {
+	var commands []Command
+	for _, direction := range []string{"north", "south", "east", "west", "up", "down"} {
+		direction := direction
+		commands = append(commands, Command{
+			Name:     direction,
+			Aliases:  []string{direction[:1]},
+			Short:    fmt.Sprintf("Walk %s.", direction),
+			Category: "World",
+			Local:    true,
+			Handler: func(g *Game, session *Session, _ *Args) []OutputEvent {
+				return g.walk(session, direction)
+			},
+		})
+	}
+	return commands
+}
+
+func handleGo(g *Game, session *Session, args *Args) []OutputEvent {
+	direction := strings.ToLower(args.String("direction"))
+	for name := range directions {
+		if name[:1] == direction {
+			direction = name
+		}
+	}
+	return g.walk(session, direction)
+}
+
+func (g *Game) walk(session *Session, direction string) []OutputEvent {
+	if _, exists := session.Room.Exits[direction]; !exists {
+		return []OutputEvent{{SessionID: session.ID, Message: "You can't go that way."}}
+	}
+	return g.moveSession(session, direction)
+}
@@ -0,0 +1,89 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var gotoCommand = Command{
+	Name:       "goto",
+	Short:      "Go straight to a room or a player.",
+	Args:       []ArgSpec{{Name: "destination", Kind: ArgRest}},
+	Usage:      "/goto <room|player>",
+	Category:   "Admin",
+	Permission: PermissionGoto,
+	Handler:    handleGoto,
+}
+
+var transferCommand = Command{
+	Name:       "transfer",
+	Short:      "Bring a player to you or send them to a room.",
+	Args:       []ArgSpec{{Name: "player"}, {Name: "room", Kind: ArgRest, Optional: true}},
+	Category:   "Admin",
+	Permission: PermissionTransfer,
+	Handler:    handleTransfer,
+}
+
+func handleGoto(g *Game, session *Session, args *Args) []OutputEvent {
+	destination := args.String("destination")
+	room := g.findRoom(destination)
+	if player, online := g.findOnline(destination); online {
+		room = player.Room
+	}
+	if room == nil {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is no room or player called %s.", destination)}}
+	}
+	if room == session.Room {
+		return []OutputEvent{{SessionID: session.ID, Message: "You are already there."}}
+	}
+	return g.teleport(session, room)
+}
+
+func handleTransfer(g *Game, session *Session, args *Args) []OutputEvent {
+	target, refusal := g.staffTarget(session, args.String("player"))
+	if refusal != nil {
+		return refusal
+	}
+	room := session.Room
+	if args.Has("room") {
+		if room = g.findRoom(args.String("room")); room == nil {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is no room called %s.", args.String("room"))}}
+		}
+	}
+	if room == target.Room {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is already in %s.", target.Name, room.Name)}}
+	}
+	messages := []OutputEvent{
+		{SessionID: session.ID, Message: fmt.Sprintf("You transfer %s to %s.", target.Name, room.Name)},
+		{SessionID: target.ID, Message: fmt.Sprintf("%s transfers you to %s.", session.Name, room.Name)},
+	}
+	return append(messages, g.teleport(target, room)...)
+}
+
+// teleport moves a session straight to a room, leaving its followers behind.
+func (g *Game) teleport(session *Session, to *Room) []OutputEvent {
+	from := session.Room
+	delete(from.Sessions, session.ID)
+	messages := g.collectBroadcastMessages(from, fmt.Sprintf("%s vanishes.", session.Name))
+	messages = append(messages, g.collectBroadcastMessages(to, fmt.Sprintf("%s appears out of thin air.", session.Name))...)
+	g.place(session, to)
+	return append(messages, OutputEvent{SessionID: session.ID, Message: to.describe(session)})
+}
+
+// findRoom resolves a room name or a unique prefix of one.
+func (g *Game) findRoom(name string) *Room {
+	name = strings.ToLower(name)
+	if room, exists := g.rooms[name]; exists {
+		return room
+	}
+	var matches []string
+	for roomName := range g.rooms {
+		if strings.HasPrefix(roomName, name) {
+			matches = append(matches, roomName)
+		}
+	}
+	if len(matches) != 1 {
+		return nil
+	}
+	return g.rooms[matches[0]]
+}
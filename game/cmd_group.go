@@ -0,0 +1,136 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var groupCommand = Command{
+	Name:  "group",
+	Short: "Form a group that travels and talks together.",
+	Args:  []ArgSpec{{Name: "action", Optional: true}, {Name: "player", Optional: true}},
+	Usage: "/group [invite <player> | accept | decline | leave | kick <player> | disband]",
+	Help: "Members follow the leader automatically.\n" +
+		"Talk to your group with /gtell <message>.",
+	Category: "Communication",
+	Handler:  handleGroup,
+}
+
+var gtellCommand = Command{
+	Name:     "gtell",
+	Aliases:  []string{"gt"},
+	Short:    "Say something to your group.",
+	Args:     []ArgSpec{{Name: "message", Kind: ArgRest}},
+	Category: "Communication",
+	Handler:  handleGtell,
+}
+
+func handleGroup(g *Game, session *Session, args *Args) []OutputEvent {
+	action := strings.ToLower(args.String("action"))
+	group := session.group
+	reply := func(message string) []OutputEvent {
+		return []OutputEvent{{SessionID: session.ID, Message: message}}
+	}
+
+	switch action {
+	case "":
+		if group == nil {
+			return reply("You are not in a group.")
+		}
+		lines := []string{fmt.Sprintf("%s's group:", group.Leader.Name)}
+		for _, member := range group.Members {
+			lines = append(lines, fmt.Sprintf("  %-20s %s", member.Name, member.Room.Name))
+		}
+		return reply(strings.Join(lines, "\n"))
+
+	case "invite":
+		if !args.Has("player") {
+			return usageError(session, g.commands["group"], "Missing <player>.")
+		}
+		if group != nil && group.Leader != session {
+			return reply("Only the group leader can invite players.")
+		}
+		target, online := g.usernames[strings.ToLower(args.String("player"))]
+		switch {
+		case !online:
+			return reply(fmt.Sprintf("User '%s' not found.", args.String("player")))
+		case target == session:
+			return reply("You cannot invite yourself.")
+		case target.group != nil:
+			return reply(fmt.Sprintf("%s is already in a group.", target.Name))
+		case target.Account.isIgnoring(session.Name):
+			return reply(fmt.Sprintf("%s is ignoring you.", target.Name))
+		}
+		target.groupInvite = session
+		return []OutputEvent{
+			{SessionID: session.ID, Message: fmt.Sprintf("You invite %s to join your group.", target.Name)},
+			{SessionID: target.ID, Message: fmt.Sprintf("%s invites you to join their group. Type /group accept to join.", session.Name), From: session.Name},
+		}
+
+	case "accept", "decline":
+		inviter := session.groupInvite
+		session.groupInvite = nil
+		if inviter == nil {
+			return reply("Nobody has invited you to a group.")
+		}
+		if action == "decline" {
+			return []OutputEvent{
+				{SessionID: session.ID, Message: fmt.Sprintf("You decline %s's invitation.", inviter.Name)},
+				{SessionID: inviter.ID, Message: fmt.Sprintf("%s declines your invitation.", session.Name)},
+			}
+		}
+		if group != nil {
+			return reply("You are already in a group. Leave it first.")
+		}
+		invite := inviter.group
+		if invite == nil {
+			// The group is only formed once someone joins it
+			invite = &Group{Leader: inviter, Members: []*Session{inviter}}
+			inviter.group = invite
+		} else if invite.Leader != inviter {
+			return reply(fmt.Sprintf("%s no longer leads a group you can join.", inviter.Name))
+		}
+		invite.Members = append(invite.Members, session)
+		session.group = invite
+		session.following = inviter
+		return invite.broadcast(fmt.Sprintf("%s joins the group.", session.Name))
+
+	case "leave":
+		if group == nil {
+			return reply("You are not in a group.")
+		}
+		return append(g.removeFromGroup(session, fmt.Sprintf("%s leaves the group.", session.Name)), reply("You leave the group.")...)
+
+	case "kick":
+		if group == nil || group.Leader != session {
+			return reply("Only the group leader can kick players.")
+		}
+		if !args.Has("player") {
+			return usageError(session, g.commands["group"], "Missing <player>.")
+		}
+		for _, member := range group.Members {
+			if member != session && strings.EqualFold(member.Name, args.String("player")) {
+				return append(g.removeFromGroup(member, fmt.Sprintf("%s was kicked from the group.", member.Name)),
+					OutputEvent{SessionID: member.ID, Message: "You were kicked from the group."})
+			}
+		}
+		return reply(fmt.Sprintf("%s is not in your group.", args.String("player")))
+
+	case "disband":
+		if group == nil || group.Leader != session {
+			return reply("Only the group leader can disband the group.")
+		}
+		return g.disbandGroup(group)
+	}
+	return usageError(session, g.commands["group"], fmt.Sprintf("Unknown action: %s.", action))
+}
+
+func handleGtell(g *Game, session *Session, args *Args) []OutputEvent {
+	if session.group == nil {
+		return []OutputEvent{{SessionID: session.ID, Message: "You are not in a group."}}
+	}
+	if refusal := g.chatRefusal(session, args.String("message")); refusal != nil {
+		return refusal
+	}
+	return from(session, session.group.broadcast(fmt.Sprintf("%s: %s", session.Name, args.String("message"))))
+}
@@ -2,41 +2,57 @@ package game
 
 import (
 	"fmt"
-	"log"
+	"sort"
 	"strings"
 )
 
-func handleHelp(g *Game, session *Session, params string, help bool) []OutputEvent {
+var helpCommand = Command{
+	Name:     "help",
+	Aliases:  []string{"?"},
+	Short:    "List available commands.",
+	Args:     []ArgSpec{{Name: "command", Optional: true}},
+	Category: "Information",
+	States:   []LoginState{StateNaming, StatePlaying},
+	Local:    true,
+	Handler:  handleHelp,
+}
 
-	if help {
+func handleHelp(g *Game, session *Session, args *Args) []OutputEvent {
+	if args.Has("command") {
+		name := strings.TrimPrefix(args.String("command"), "/")
+		command, problem := g.findCommand(session, name)
+		if command == nil {
+			return []OutputEvent{{
+				SessionID: session.ID,
+				Message:   problem,
+			}}
+		}
 		return []OutputEvent{{
 			SessionID: session.ID,
-			Message:   "List available commands.\nUsage: /help [<command>]",
+			Message:   command.helpText(),
 		}}
 	}
-	if len(params) > 0 {
-		parts := strings.Split(params, " ")
-		if len(parts) > 0 {
-			cmd := parts[0]
-			log.Printf("User %s issued help command for %s. '%s': %+v, %d", session.Name, cmd, params, parts, len(parts))
 
-			if command, exists := g.commands[cmd]; exists {
-				return command(g, session, params, true)
-			} else {
-				return []OutputEvent{{
-					SessionID: session.ID,
-					Message:   fmt.Sprintf("Unknown command: %s", cmd),
-				}}
-			}
+	categories := make(map[string][]string)
+	for _, command := range g.commandList() {
+		if session.canUse(command) {
+			categories[command.Category] = append(categories[command.Category], "/"+command.Name)
 		}
 	}
-	commandNames := []string{}
-	for name := range g.commands {
-		commandNames = append(commandNames, fmt.Sprintf("/%s", name))
+	categoryNames := make([]string, 0, len(categories))
+	for category := range categories {
+		categoryNames = append(categoryNames, category)
+	}
+	sort.Strings(categoryNames)
+
+	lines := []string{"Available commands:"}
+	for _, category := range categoryNames {
+		lines = append(lines, fmt.Sprintf("  %s: %s", category, strings.Join(categories[category], ", ")))
 	}
+	lines = append(lines, "Type /help <command> for more information.")
 
 	return []OutputEvent{{
 		SessionID: session.ID,
-		Message:   fmt.Sprintf("Available commands: %s", strings.Join(commandNames, ", ")),
+		Message:   strings.Join(lines, "\n"),
 	}}
 }
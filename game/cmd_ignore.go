@@ -0,0 +1,95 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// PermissionOverrideIgnore lets staff reach players who ignore them.
+const PermissionOverrideIgnore Permission = "override-ignore"
+
+const maxIgnored = 100 // how many players one player may ignore
+
+var ignoreCommand = Command{
+	Name:     "ignore",
+	Short:    "Hide messages from a player, or list who you ignore.",
+	Args:     []ArgSpec{{Name: "player", Optional: true}},
+	Help:     "Ignored players' whispers, says, emotes and channel messages are not shown to you. Staff messages always get through.",
+	Category: "Settings",
+	Handler:  handleIgnore,
+}
+
+var unignoreCommand = Command{
+	Name:     "unignore",
+	Short:    "Stop ignoring a player.",
+	Args:     []ArgSpec{{Name: "player"}},
+	Category: "Settings",
+	Handler:  handleUnignore,
+}
+
+func handleIgnore(g *Game, session *Session, args *Args) []OutputEvent {
+	account := session.Account
+	if !args.Has("player") {
+		if len(account.Ignored) == 0 {
+			return []OutputEvent{{SessionID: session.ID, Message: "You are not ignoring anyone."}}
+		}
+		return []OutputEvent{{SessionID: session.ID, Message: "You are ignoring: " + strings.Join(account.Ignored, ", ")}}
+	}
+
+	name := strings.ToLower(args.String("player"))
+	switch {
+	case name == strings.ToLower(session.Name):
+		return []OutputEvent{{SessionID: session.ID, Message: "You cannot ignore yourself."}}
+	case account.isIgnoring(name):
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are already ignoring %s.", name)}}
+	case !validName(name) || !g.accounts.exists(name):
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("User '%s' not found.", args.String("player"))}}
+	case len(account.Ignored) >= maxIgnored:
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You cannot ignore more than %d players.", maxIgnored)}}
+	}
+
+	account.Ignored = append(account.Ignored, name)
+	slices.Sort(account.Ignored)
+	if err := g.accounts.save(account); err != nil {
+		withSession(storageLog, session).Error("Failed to save ignore list", "err", err)
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are now ignoring %s.", name)}}
+}
+
+func handleUnignore(g *Game, session *Session, args *Args) []OutputEvent {
+	account := session.Account
+	name := strings.ToLower(args.String("player"))
+	if !account.isIgnoring(name) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are not ignoring %s.", name)}}
+	}
+
+	account.Ignored = slices.DeleteFunc(account.Ignored, func(ignored string) bool { return ignored == name })
+	if err := g.accounts.save(account); err != nil {
+		withSession(storageLog, session).Error("Failed to save ignore list", "err", err)
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are no longer ignoring %s.", name)}}
+}
+
+func (a *Account) isIgnoring(name string) bool {
+	return slices.Contains(a.Ignored, strings.ToLower(name))
+}
+
+// from marks events as caused by the session, so players ignoring it do
+// not get them.
+func from(session *Session, events []OutputEvent) []OutputEvent {
+	for i := range events {
+		events[i].From = session.Name
+	}
+	return events
+}
+
+// isIgnoring reports whether recipient should not be shown event because
+// they ignore the player who caused it. Staff are never ignored.
+func (g *Game) isIgnoring(recipient *Session, event OutputEvent) bool {
+	if event.From == "" || recipient.Account == nil || !recipient.Account.isIgnoring(event.From) {
+		return false
+	}
+	sender, online := g.usernames[strings.ToLower(event.From)]
+	return !online || !sender.hasPermission(PermissionOverrideIgnore)
+}
@@ -0,0 +1,66 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var kickCommand = Command{
+	Name:       "kick",
+	Short:      "Disconnect a player.",
+	Args:       []ArgSpec{{Name: "player"}, {Name: "reason", Kind: ArgRest, Optional: true}},
+	Category:   "Admin",
+	Permission: PermissionKick,
+	Handler:    handleKick,
+}
+
+func handleKick(g *Game, session *Session, args *Args) []OutputEvent {
+	target, refusal := g.staffTarget(session, args.String("player"))
+	if refusal != nil {
+		return refusal
+	}
+	notice := fmt.Sprintf("You have been kicked by %s.", session.Name)
+	if reason := args.String("reason"); reason != "" {
+		notice = fmt.Sprintf("You have been kicked by %s: %s", session.Name, reason)
+	}
+	messages := []OutputEvent{
+		{SessionID: target.ID, Message: notice, Quit: true},
+		{SessionID: session.ID, Message: fmt.Sprintf("You kicked %s.", target.Name)},
+	}
+	return g.endSession(target, messages)
+}
+
+// findOnline returns the logged in player with the given name.
+func (g *Game) findOnline(name string) (*Session, bool) {
+	session, online := g.usernames[strings.ToLower(name)]
+	return session, online
+}
+
+// staffTarget finds an online player the session outranks, or returns a
+// message explaining why there is none.
+func (g *Game) staffTarget(session *Session, name string) (*Session, []OutputEvent) {
+	target, online := g.findOnline(name)
+	if !online {
+		return nil, []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is not online.", name)}}
+	}
+	if !session.Account.outranks(target.Account) {
+		return nil, []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You cannot do that to %s.", target.Name)}}
+	}
+	return target, nil
+}
+
+// staffTargetAccount is like staffTarget but also finds offline players.
+func (g *Game) staffTargetAccount(session *Session, name string) (*Account, []OutputEvent) {
+	if !validName(name) || !g.accounts.exists(name) {
+		return nil, []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("User '%s' not found.", name)}}
+	}
+	account, err := g.accounts.load(name)
+	if err != nil {
+		storageLog.Error("Failed to load account", "account", name, "err", err)
+		return nil, []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Could not look up %s.", name)}}
+	}
+	if !session.Account.outranks(account) {
+		return nil, []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You cannot do that to %s.", account.Name)}}
+	}
+	return account, nil
+}
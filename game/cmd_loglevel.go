@@ -0,0 +1,39 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"mud/logging"
+)
+
+// PermissionLogLevel lets staff change how much the server logs.
+const PermissionLogLevel Permission = "log-level"
+
+var loglevelCommand = Command{
+	Name:       "loglevel",
+	Short:      "Show or change how much each part of the server logs.",
+	Args:       []ArgSpec{{Name: "subsystem", Optional: true}, {Name: "level", Optional: true}},
+	Help:       "Levels are debug, info, warn and error. Example: /loglevel telnet debug",
+	Category:   "Admin",
+	Permission: PermissionLogLevel,
+	Handler:    handleLoglevel,
+}
+
+func handleLoglevel(g *Game, session *Session, args *Args) []OutputEvent {
+	if !args.Has("subsystem") {
+		return []OutputEvent{{SessionID: session.ID, Message: "Log levels:\n" + strings.Join(logging.Levels(), "\n")}}
+	}
+	if !args.Has("level") {
+		return usageError(session, g.commands["loglevel"], "Missing <level>.")
+	}
+	level, err := logging.ParseLevel(args.String("level"))
+	if err != nil {
+		return usageError(session, g.commands["loglevel"], "The level must be debug, info, warn or error.")
+	}
+	if err := logging.SetLevel(args.String("subsystem"), level); err != nil {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is no subsystem called %s.", args.String("subsystem"))}}
+	}
+	withSession(adminLog, session).Info("Log level changed", "subsystem", args.String("subsystem"), "level", level)
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s now logs at %s.", args.String("subsystem"), strings.ToLower(level.String()))}}
+}
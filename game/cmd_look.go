@@ -0,0 +1,14 @@
+package game
+
+var lookCommand = Command{
+	Name:     "look",
+	Aliases:  []string{"l"},
+	Short:    "Look around the room you are in.",
+	Category: "World",
+	Local:    true,
+	Handler:  handleLook,
+}
+
+func handleLook(_ *Game, session *Session, _ *Args) []OutputEvent {
+	return []OutputEvent{{SessionID: session.ID, Message: session.Room.describe(session)}}
+}
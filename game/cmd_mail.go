@@ -0,0 +1,155 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const maxMailbox = 100 // how many messages a mailbox holds
+
+var mailCommand = Command{
+	Name:  "mail",
+	Short: "Send and read mail to other players.",
+	Args:  []ArgSpec{{Name: "action", Optional: true}, {Name: "arguments", Kind: ArgRest, Optional: true}},
+	Usage: "/mail [list | read <number> | delete <number> | send <player> <subject>]",
+	Help: "Mail is kept until you delete it, and players are told about new mail when they log in.\n" +
+		"/mail send opens an editor for the message body.",
+	Category: "Communication",
+	Handler:  handleMail,
+}
+
+// Mail is a message in a player's mailbox.
+type Mail struct {
+	From    string    `json:"from"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	Sent    time.Time `json:"sent"`
+	Read    bool      `json:"read"`
+}
+
+func handleMail(g *Game, session *Session, args *Args) []OutputEvent {
+	command := g.commands["mail"]
+	action := strings.ToLower(args.String("action"))
+	var specs []ArgSpec
+	switch action {
+	case "", "list":
+		return listMail(session)
+	case "read", "delete":
+		specs = []ArgSpec{{Name: "number", Kind: ArgNumber}}
+	case "send":
+		specs = []ArgSpec{{Name: "player"}, {Name: "subject", Kind: ArgRest}}
+	default:
+		return usageError(session, command, fmt.Sprintf("Unknown action: %s.", action))
+	}
+
+	actionArgs, err := parseArgs(specs, args.String("arguments"))
+	if err != nil {
+		return usageError(session, command, err.Error())
+	}
+	if action == "send" {
+		return sendMail(g, session, actionArgs.String("player"), actionArgs.String("subject"))
+	}
+
+	mailbox := session.Account.Mail
+	n := actionArgs.Int("number")
+	if n < 1 || n > len(mailbox) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("There is no message %d.", n)}}
+	}
+	if action == "delete" {
+		session.Account.Mail = append(mailbox[:n-1], mailbox[n:]...)
+		g.saveMailbox(session.Account)
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Message %d deleted.", n)}}
+	}
+
+	mail := &mailbox[n-1]
+	if !mail.Read {
+		mail.Read = true
+		g.saveMailbox(session.Account)
+	}
+	return []OutputEvent{{
+		SessionID: session.ID,
+		Message: fmt.Sprintf("From: %s\nDate: %s\nSubject: %s\n\n%s",
+			mail.From, mail.Sent.Format("2006-01-02 15:04"), mail.Subject, mail.Body),
+	}}
+}
+
+func listMail(session *Session) []OutputEvent {
+	mailbox := session.Account.Mail
+	if len(mailbox) == 0 {
+		return []OutputEvent{{SessionID: session.ID, Message: "Your mailbox is empty."}}
+	}
+	lines := []string{"Your mail:"}
+	for i, mail := range mailbox {
+		marker := " "
+		if !mail.Read {
+			marker = "N"
+		}
+		lines = append(lines, fmt.Sprintf("%s %2d. %-20s %s  %s", marker, i+1, mail.From, mail.Sent.Format("2006-01-02"), mail.Subject))
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+}
+
+func sendMail(g *Game, session *Session, recipient, subject string) []OutputEvent {
+	if !validName(recipient) || !g.accounts.exists(recipient) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("User '%s' not found.", recipient)}}
+	}
+
+	if refusal := g.mutedRefusal(session); refusal != nil {
+		return refusal
+	}
+
+	title := fmt.Sprintf("Writing to %s: %s", recipient, subject)
+	return g.startEditor(session, title, func(g *Game, session *Session, body string) []OutputEvent {
+		if refusal := g.chatRefusal(session, body); refusal != nil {
+			return refusal
+		}
+		account, err := g.accounts.load(recipient)
+		if err != nil {
+			storageLog.Error("Failed to load account", "account", recipient, "err", err)
+			return []OutputEvent{{SessionID: session.ID, Message: "Your mail could not be delivered."}}
+		}
+		if account.isIgnoring(session.Name) && !session.hasPermission(PermissionOverrideIgnore) {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is ignoring you.", account.Name)}}
+		}
+		if len(account.Mail) >= maxMailbox {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s's mailbox is full.", account.Name)}}
+		}
+
+		account.Mail = append(account.Mail, Mail{From: session.Name, Subject: subject, Body: body, Sent: g.clock.Now()})
+		g.saveMailbox(account)
+
+		messages := []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Mail sent to %s.", account.Name)}}
+		if target, online := g.usernames[strings.ToLower(account.Name)]; online {
+			messages = append(messages, OutputEvent{
+				SessionID: target.ID,
+				Message:   fmt.Sprintf("You have new mail from %s.", session.Name),
+				From:      session.Name,
+			})
+		}
+		return messages
+	})
+}
+
+// announceMail tells a player who just logged in about unread mail.
+func announceMail(session *Session) []OutputEvent {
+	unread := 0
+	for _, mail := range session.Account.Mail {
+		if !mail.Read {
+			unread++
+		}
+	}
+	if unread == 0 {
+		return nil
+	}
+	return []OutputEvent{{
+		SessionID: session.ID,
+		Message:   fmt.Sprintf("You have %d unread mail message(s). Type /mail list to see them.", unread),
+	}}
+}
+
+func (g *Game) saveMailbox(account *Account) {
+	if err := g.accounts.save(account); err != nil {
+		storageLog.Error("Failed to save mailbox", "account", account.Name, "err", err)
+	}
+}
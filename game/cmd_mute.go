@@ -0,0 +1,92 @@
+package game
+
+import (
+	"fmt"
+	"time"
+)
+
+var muteCommand = Command{
+	Name:       "mute",
+	Short:      "Stop a player from talking to others.",
+	Args:       []ArgSpec{{Name: "player"}, {Name: "duration", Optional: true}},
+	Help:       "Without a duration such as 30m, 12h or 7d, the player stays muted until unmuted.",
+	Category:   "Admin",
+	Permission: PermissionMute,
+	Handler:    handleMute,
+}
+
+var unmuteCommand = Command{
+	Name:       "unmute",
+	Short:      "Let a muted player talk again.",
+	Args:       []ArgSpec{{Name: "player"}},
+	Category:   "Admin",
+	Permission: PermissionMute,
+	Handler:    handleUnmute,
+}
+
+func handleMute(g *Game, session *Session, args *Args) []OutputEvent {
+	var d time.Duration
+	if args.Has("duration") {
+		var ok bool
+		if d, ok = parseDuration(args.String("duration")); !ok {
+			return usageError(session, g.commands["mute"], "The duration must look like 30m, 12h or 7d.")
+		}
+	}
+	account, refusal := g.staffTargetAccount(session, args.String("player"))
+	if refusal != nil {
+		return refusal
+	}
+	account.Muted, account.MutedUntil = true, time.Time{}
+	reply := fmt.Sprintf("%s is muted until unmuted.", account.Name)
+	if d > 0 {
+		account.MutedUntil = g.clock.Now().Add(d)
+		reply = fmt.Sprintf("%s is muted until %s.", account.Name, account.MutedUntil.Format("2006-01-02 15:04"))
+	}
+	if err := g.accounts.save(account); err != nil {
+		storageLog.Error("Failed to save mute", "account", account.Name, "err", err)
+	}
+
+	messages := []OutputEvent{{SessionID: session.ID, Message: reply}}
+	if target, online := g.findOnline(account.Name); online {
+		messages = append(messages, OutputEvent{SessionID: target.ID, Message: fmt.Sprintf("You have been muted by %s.", session.Name)})
+	}
+	return messages
+}
+
+func handleUnmute(g *Game, session *Session, args *Args) []OutputEvent {
+	account, refusal := g.staffTargetAccount(session, args.String("player"))
+	if refusal != nil {
+		return refusal
+	}
+	if !account.muted(g.clock.Now()) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is not muted.", account.Name)}}
+	}
+	account.Muted, account.MutedUntil = false, time.Time{}
+	if err := g.accounts.save(account); err != nil {
+		storageLog.Error("Failed to save unmute", "account", account.Name, "err", err)
+	}
+
+	messages := []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is no longer muted.", account.Name)}}
+	if target, online := g.findOnline(account.Name); online {
+		messages = append(messages, OutputEvent{SessionID: target.ID, Message: "You are no longer muted."})
+	}
+	return messages
+}
+
+// muted reports whether the account may not talk to others at now.
+func (a *Account) muted(now time.Time) bool {
+	return a.Muted && (a.MutedUntil.IsZero() || now.Before(a.MutedUntil))
+}
+
+// mutedRefusal returns the message telling a muted player they cannot
+// talk, or nil if they can.
+func (g *Game) mutedRefusal(session *Session) []OutputEvent {
+	if session.Account == nil || !session.Account.muted(g.clock.Now()) {
+		return nil
+	}
+	message := "You are muted."
+	if !session.Account.MutedUntil.IsZero() {
+		message = fmt.Sprintf("You are muted until %s.", session.Account.MutedUntil.Format("2006-01-02 15:04"))
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: message}}
+}
@@ -0,0 +1,67 @@
+package game
+
+import "fmt"
+
+// minPasswordLength is the length of the shortest password allowed.
+const minPasswordLength = 6
+
+var passwordCommand = Command{
+	Name:  "password",
+	Short: "Set or change the password of your account.",
+	Args:  []ArgSpec{{Name: "password"}, {Name: "new", Optional: true}},
+	Usage: "/password [<current password>] <new password>",
+	Help: "Once your account has a password, nobody can log in as you without it.\n" +
+		"To change a password, give the current one first. Staff accounts must have a password.",
+	Category: "Settings",
+	Handler:  handlePassword,
+}
+
+func handlePassword(g *Game, session *Session, args *Args) []OutputEvent {
+	account := session.Account
+	current, password := "", args.String("password")
+	if account.Password != "" {
+		if !args.Has("new") {
+			return usageError(session, g.commands["password"], "Give your current password, then the new one.")
+		}
+		current, password = password, args.String("new")
+	} else if args.Has("new") {
+		return usageError(session, g.commands["password"], "You have no password yet; give just the new one.")
+	}
+	if len(password) < minPasswordLength {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Passwords must be at least %d characters long.", minPasswordLength)}}
+	}
+
+	// Hashing is slow, so it is done away from the world lock
+	old := account.Password
+	g.offload(session, func() job {
+		right := old == "" || checkPassword(old, current)
+		hash := ""
+		if right {
+			hash = hashPassword(password)
+		}
+		return job{exclusive: always, run: func() []OutputEvent {
+			return setPassword(g, session, old, hash)
+		}}
+	})
+	return nil
+}
+
+// setPassword gives the session's account a new password hash, unless the
+// current password given was wrong, in which case hash is empty.
+func setPassword(g *Game, session *Session, old, hash string) []OutputEvent {
+	account := session.Account
+	if hash == "" {
+		g.audit(session, "password-refused", account.Name, "wrong password")
+		return []OutputEvent{{SessionID: session.ID, Message: "That is not your current password."}}
+	}
+	if account.Password != old {
+		return []OutputEvent{{SessionID: session.ID, Message: "Your password was changed meanwhile. Please try again."}}
+	}
+	account.Password = hash
+	g.audit(session, "password", account.Name, "")
+	if err := g.accounts.save(account); err != nil {
+		withSession(storageLog, session).Error("Failed to save password", "err", err)
+		return []OutputEvent{{SessionID: session.ID, Message: "Your password could not be saved. Please try again later."}}
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: "Your password has been set."}}
+}
@@ -1,17 +1,17 @@
 package game
 
-func handleQuit(g *Game, session *Session, params string, help bool) []OutputEvent {
-
-	if help {
-		return []OutputEvent{{
-			SessionID: session.ID,
-			Message:   "Quit the game.\nUsage: /quit",
-		}}
-	}
+var quitCommand = Command{
+	Name:     "quit",
+	Short:    "Quit the game.",
+	Category: "System",
+	States:   []LoginState{StateNaming, StatePlaying},
+	Handler:  handleQuit,
+}
 
+func handleQuit(g *Game, session *Session, _ *Args) []OutputEvent {
 	return []OutputEvent{{
 		SessionID: session.ID,
 		Message:   "Goodbye!",
-		Quit: 		true,
+		Quit:      true,
 	}}
-}
\ No newline at end of file
+}
@@ -0,0 +1,17 @@
+package game
+
+var replyCommand = Command{
+	Name:     "reply",
+	Short:    "Whisper back to whoever last whispered to you.",
+	Args:     []ArgSpec{{Name: "message", Kind: ArgRest}},
+	Category: "Communication",
+	Local:    true,
+	Handler:  handleReply,
+}
+
+func handleReply(g *Game, session *Session, args *Args) []OutputEvent {
+	if session.replyTo == "" {
+		return []OutputEvent{{SessionID: session.ID, Message: "Nobody has whispered to you yet."}}
+	}
+	return g.whisper(session, session.replyTo, args.String("message"))
+}
@@ -0,0 +1,67 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+var roleCommand = Command{
+	Name:  "role",
+	Short: "Show or change a player's role.",
+	Args:  []ArgSpec{{Name: "player"}, {Name: "role", Optional: true}},
+	Help: "Roles from least to most trusted: player, builder, moderator, admin, owner.\n" +
+		"You can only hand out roles below your own, and staff roles only to players with a password.",
+	Category:   "Admin",
+	Permission: PermissionSetRole,
+	Handler:    handleRole,
+}
+
+func handleRole(g *Game, session *Session, args *Args) []OutputEvent {
+	name := args.String("player")
+	if !args.Has("role") {
+		if !validName(name) || !g.accounts.exists(name) {
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("User '%s' not found.", name)}}
+		}
+		account, err := g.accounts.load(name)
+		if err != nil {
+			storageLog.Error("Failed to load account", "account", name, "err", err)
+			return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Could not look up %s.", name)}}
+		}
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is %s.", account.Name, withArticle(string(account.role())))}}
+	}
+
+	role, ok := parseRole(args.String("role"))
+	if !ok {
+		return usageError(session, g.commands["role"], fmt.Sprintf("There is no role called %s.", args.String("role")))
+	}
+	if role.rank() >= session.Account.role().rank() {
+		return []OutputEvent{{SessionID: session.ID, Message: "You can only hand out roles below your own."}}
+	}
+	account, refusal := g.staffTargetAccount(session, name)
+	if refusal != nil {
+		return refusal
+	}
+	if role != RolePlayer && account.Password == "" {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s must set a password with /password before they can be given a staff role.", account.Name)}}
+	}
+	g.audit(session, "role", account.Name, fmt.Sprintf("%s to %s", account.role(), role))
+	account.Role = role
+	if err := g.accounts.save(account); err != nil {
+		storageLog.Error("Failed to save role", "account", account.Name, "err", err)
+	}
+
+	messages := []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is now %s.", account.Name, withArticle(string(role)))}}
+	if target, online := g.findOnline(account.Name); online {
+		g.applyRole(target)
+		messages = append(messages, OutputEvent{SessionID: target.ID, Message: fmt.Sprintf("%s made you %s.", session.Name, withArticle(string(role)))})
+	}
+	return messages
+}
+
+// withArticle puts "a" or "an" in front of word.
+func withArticle(word string) string {
+	if strings.ContainsAny(word[:1], "aeiouAEIOU") {
+		return "an " + word
+	}
+	return "a " + word
+}
@@ -0,0 +1,27 @@
+package game
+
+import (
+	"fmt"
+)
+
+var sayCommand = Command{
+	Name:     "say",
+	Aliases:  []string{"'"},
+	Short:    "Say something to everyone in the room.",
+	Args:     []ArgSpec{{Name: "message", Kind: ArgRest}},
+	Category: "Communication",
+	Local:    true,
+	Handler:  handleSay,
+}
+
+func handleSay(g *Game, session *Session, args *Args) []OutputEvent {
+	return g.say(session, args.String("message"))
+}
+
+// say broadcasts a message from the session to everyone in its room.
+func (g *Game) say(session *Session, message string) []OutputEvent {
+	if refusal := g.chatRefusal(session, message); refusal != nil {
+		return refusal
+	}
+	return from(session, g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s says: %s", session.Name, message)))
+}
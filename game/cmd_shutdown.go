@@ -0,0 +1,88 @@
+package game
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+var shutdownCommand = Command{
+	Name:       "shutdown",
+	Short:      "Stop the server.",
+	Args:       []ArgSpec{{Name: "seconds", Optional: true}},
+	Usage:      "/shutdown [<seconds>|cancel]",
+	Help:       "Players are warned before the server stops. /shutdown cancel calls it off.",
+	Category:   "Admin",
+	Permission: PermissionShutdown,
+	Handler:    handleShutdown,
+}
+
+func handleShutdown(g *Game, session *Session, args *Args) []OutputEvent {
+	when := args.String("seconds")
+	if when == "cancel" {
+		if g.countdown == nil || !g.countdown.Stop() {
+			return []OutputEvent{{SessionID: session.ID, Message: "No shutdown is scheduled."}}
+		}
+		g.countdown = nil
+		return []OutputEvent{{Message: fmt.Sprintf("[Broadcast] %s called off the shutdown.", session.Name)}}
+	}
+
+	seconds := 0
+	if args.Has("seconds") {
+		var err error
+		if seconds, err = strconv.Atoi(when); err != nil || seconds < 0 {
+			return usageError(session, g.commands["shutdown"], "<seconds> must be a number.")
+		}
+	}
+	if g.countdown != nil {
+		g.countdown.Stop()
+	}
+	withSession(adminLog, session).Info("Shutdown scheduled", "seconds", seconds)
+	// The timer fires once the lock held while handling this command is
+	// released, even without a delay.
+	g.countdown = g.clock.AfterFunc(time.Duration(seconds)*time.Second, g.Shutdown)
+	if seconds == 0 {
+		return nil
+	}
+	return []OutputEvent{{Message: fmt.Sprintf("[Broadcast] The server will shut down in %d seconds.", seconds)}}
+}
+
+// Shutdown says goodbye to everyone, saves their accounts and stops the
+// game. Input that arrives afterwards is ignored, so the data directory can
+// be handed to a new game straight away.
+func (g *Game) Shutdown() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	select {
+	case <-g.done:
+		return
+	default:
+	}
+	if g.countdown != nil {
+		g.countdown.Stop()
+	}
+	for _, session := range g.sessions {
+		if session.loggedIn() {
+			session.Account.LastSeen = g.clock.Now()
+			if err := g.accounts.save(session.Account); err != nil {
+				withSession(storageLog, session).Error("Failed to save account", "err", err)
+			}
+		}
+		g.deliver(session, OutputEvent{SessionID: session.ID, Message: "The server is shutting down. Goodbye!", Quit: true})
+	}
+	// The connections close on their own; their sessions are already gone
+	clear(g.sessions)
+	clear(g.usernames)
+	for _, room := range g.rooms {
+		room.actor.stop()
+	}
+	for _, channel := range g.channels {
+		channel.actor.stop()
+	}
+	close(g.done)
+}
+
+// Done returns a channel that is closed once the game has shut down.
+func (g *Game) Done() <-chan struct{} {
+	return g.done
+}
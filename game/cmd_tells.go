@@ -0,0 +1,23 @@
+package game
+
+import (
+	"strings"
+)
+
+var tellsCommand = Command{
+	Name:     "tells",
+	Short:    "Show the whispers you sent and received this session.",
+	Category: "Communication",
+	Local:    true,
+	Handler:  handleTells,
+}
+
+func handleTells(_ *Game, session *Session, _ *Args) []OutputEvent {
+	if len(session.tells) == 0 {
+		return []OutputEvent{{SessionID: session.ID, Message: "You have not whispered with anyone yet."}}
+	}
+	return []OutputEvent{{
+		SessionID: session.ID,
+		Message:   "Recent whispers:\n" + strings.Join(session.tells, "\n"),
+	}}
+}
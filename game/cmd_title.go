@@ -0,0 +1,31 @@
+package game
+
+import (
+	"fmt"
+)
+
+const maxTitleLength = 40
+
+var titleCommand = Command{
+	Name:     "title",
+	Short:    "Set the title shown after your name in /who and /finger.",
+	Args:     []ArgSpec{{Name: "title", Kind: ArgRest, Optional: true}},
+	Help:     "Without a title, your title is cleared.",
+	Category: "Settings",
+	Handler:  handleTitle,
+}
+
+func handleTitle(g *Game, session *Session, args *Args) []OutputEvent {
+	title := args.String("title")
+	if len(title) > maxTitleLength {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Titles cannot be longer than %d characters.", maxTitleLength)}}
+	}
+	session.Account.Title = title
+	if err := g.accounts.save(session.Account); err != nil {
+		withSession(storageLog, session).Error("Failed to save title", "err", err)
+	}
+	if title == "" {
+		return []OutputEvent{{SessionID: session.ID, Message: "Your title is cleared."}}
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You are now %s %s.", session.Name, title)}}
+}
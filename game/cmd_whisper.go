@@ -2,40 +2,149 @@ package game
 
 import (
 	"fmt"
-	"log"
 	"strings"
+	"time"
 )
 
-func handleWhisper(g *Game, session *Session, params string, help bool) []OutputEvent {
-	parts := strings.Split(params, " ")
-	if help || len(parts) < 2 {
-		return []OutputEvent{{
-			SessionID: session.ID,
-			Message:   "Say something privately\nUsage: /whisper <username> <message>",
-		}}
+const (
+	tellHistorySize = 20 // how many whispers /tells remembers per session
+	maxOfflineTells = 20 // how many whispers can wait for an offline player
+)
+
+var whisperCommand = Command{
+	Name:     "whisper",
+	Aliases:  []string{"tell"},
+	Short:    "Say something privately.",
+	Args:     []ArgSpec{{Name: "username"}, {Name: "message", Kind: ArgRest}},
+	Help:     "If the player is offline, the message is delivered when they next log in.",
+	Category: "Communication",
+	Local:    true,
+	Handler:  handleWhisper,
+}
+
+// Tell is a whisper waiting for an offline player.
+type Tell struct {
+	From    string    `json:"from"`
+	Message string    `json:"message"`
+	Sent    time.Time `json:"sent"`
+}
+
+func handleWhisper(g *Game, session *Session, args *Args) []OutputEvent {
+	return g.whisper(session, args.String("username"), args.String("message"))
+}
+
+// whisper sends a private message from session to the named player, or
+// queues it on their account if they are offline. The message is handed
+// over by the actor of the target's room, as the target may be anywhere.
+func (g *Game) whisper(session *Session, targetUsername, message string) []OutputEvent {
+	withSession(chatLog, session).Debug("Whisper", "to", targetUsername)
+	if refusal := g.chatRefusal(session, targetUsername+" "+message); refusal != nil {
+		return refusal
+	}
+	targetSession, exists := g.usernames[strings.ToLower(targetUsername)]
+	if g.logPrivate {
+		withSession(chatLog, session).Info("Private message", "to", targetUsername, "message", message)
 	}
-	targetUsername := parts[0]
-	message := strings.Join(parts[1:], " ")
-	
-	log.Printf("User %s issued say command %+v", session.Name, parts)
-	targetSession, exists := g.usernames[targetUsername]
-	log.Printf("User %s wants to send a private message to %s: %s", session.Name, targetUsername, message)
 	if !exists {
+		// Queuing the message changes the target's account, which needs the
+		// world to itself
+		session.post(job{exclusive: always, run: func() []OutputEvent {
+			return g.whisperOffline(session, targetUsername, message)
+		}})
+		return nil
+	}
+	if targetSession.Account.isIgnoring(session.Name) && !session.hasPermission(PermissionOverrideIgnore) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is ignoring you.", targetSession.Name)}}
+	}
+
+	session.rememberTell(fmt.Sprintf("You whispered to %s: %s", targetSession.Name, message))
+	hand := func() []OutputEvent {
+		targetSession.replyTo = session.Name
+		targetSession.rememberTell(fmt.Sprintf("%s whispers: %s", session.Name, message))
+		messages := []OutputEvent{{
+			SessionID: targetSession.ID,
+			Message:   fmt.Sprintf("%s whispers: %s", session.Name, message),
+			From:      session.Name,
+		}}
+		if targetSession.afk {
+			notice := fmt.Sprintf("%s is AFK and may not see your message.", targetSession.Name)
+			if targetSession.afkMessage != "" {
+				notice = fmt.Sprintf("%s is AFK: %s", targetSession.Name, targetSession.afkMessage)
+			}
+			messages = append(messages, OutputEvent{SessionID: session.ID, Message: notice})
+		}
+		return messages
+	}
+
+	var messages []OutputEvent
+	if targetSession.home.Load() == session.Room {
+		// The target's room is this one, so this actor looks after them too
+		messages = hand()
+	} else {
+		targetSession.post(job{run: hand})
+	}
+	return append(messages, OutputEvent{
+		SessionID: session.ID,
+		Message:   fmt.Sprintf("You whispered to %s: %s", targetUsername, message),
+	})
+}
+
+func (g *Game) whisperOffline(session *Session, targetUsername, message string) []OutputEvent {
+	if !validName(targetUsername) || !g.accounts.exists(targetUsername) {
 		return []OutputEvent{{
 			SessionID: session.ID,
 			Message:   fmt.Sprintf("User '%s' not found.", targetUsername),
 		}}
 	}
+	account, err := g.accounts.load(targetUsername)
+	if err != nil {
+		storageLog.Error("Failed to load account", "account", targetUsername, "err", err)
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Your message to %s could not be delivered.", targetUsername)}}
+	}
+	if account.isIgnoring(session.Name) && !session.hasPermission(PermissionOverrideIgnore) {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is ignoring you.", account.Name)}}
+	}
+	if len(account.Tells) >= maxOfflineTells {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("%s is offline and has too many messages waiting.", account.Name)}}
+	}
 
-	// Send message to target user
-	return []OutputEvent{
-		{
-			SessionID: targetSession.ID,
-			Message:   fmt.Sprintf("%s whispers: %s", session.Name, message),
-		},
-		{
-			SessionID: session.ID,
-			Message:   fmt.Sprintf("You whispered to %s: %s", targetUsername, message),
-		},
+	account.Tells = append(account.Tells, Tell{From: session.Name, Message: message, Sent: g.clock.Now()})
+	if err := g.accounts.save(account); err != nil {
+		storageLog.Error("Failed to save offline tell", "account", account.Name, "err", err)
+	}
+	session.rememberTell(fmt.Sprintf("You whispered to %s (offline): %s", account.Name, message))
+	return []OutputEvent{{
+		SessionID: session.ID,
+		Message:   fmt.Sprintf("%s is offline. Your message will be delivered when they log in.", account.Name),
+	}}
+}
+
+// deliverOfflineTells hands a player who just logged in the whispers that
+// arrived while they were away.
+func (g *Game) deliverOfflineTells(session *Session) []OutputEvent {
+	tells := session.Account.Tells
+	if len(tells) == 0 {
+		return nil
+	}
+
+	lines := []string{fmt.Sprintf("You have %d message(s) from while you were away:", len(tells))}
+	for _, tell := range tells {
+		line := fmt.Sprintf("%s whispers: %s", tell.From, tell.Message)
+		lines = append(lines, fmt.Sprintf("[%s] %s", tell.Sent.Format("2006-01-02 15:04"), line))
+		session.rememberTell(line)
+		session.replyTo = tell.From
+	}
+
+	session.Account.Tells = nil
+	if err := g.accounts.save(session.Account); err != nil {
+		withSession(storageLog, session).Error("Failed to save delivered tells", "err", err)
+	}
+	return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+}
+
+func (s *Session) rememberTell(line string) {
+	s.tells = append(s.tells, line)
+	if len(s.tells) > tellHistorySize {
+		s.tells = s.tells[len(s.tells)-tellHistorySize:]
 	}
-}
\ No newline at end of file
+}
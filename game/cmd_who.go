@@ -2,25 +2,148 @@ package game
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 )
 
-func handleListUsersInRoom(_ *Game, session *Session, _ string, help bool) []OutputEvent {
-	if help {
-		return []OutputEvent{
-			{
-				SessionID: session.ID,
-				Message:   "List users in the room.\nUsage: /who",
-			},
+var whoCommand = Command{
+	Name:  "who",
+	Short: "List the players in the game.",
+	Args:  []ArgSpec{{Name: "filters", Kind: ArgRest, Optional: true}},
+	Usage: "/who [here] [class <class>] [room <room>] [group] [staff] [afk] [sort name|idle] [<name>]",
+	Help: "Filters can be combined; a plain word matches the start of player names.\n" +
+		"Example: /who class mage sort idle",
+	Category: "Information",
+	Handler:  handleWho,
+}
+
+// whoFilter holds the filters given to /who.
+type whoFilter struct {
+	here, group, staff, afk bool
+	class, room, name       string
+	sortBy                  string
+}
+
+func handleWho(g *Game, session *Session, args *Args) []OutputEvent {
+	filter, problem := parseWhoFilter(args.String("filters"))
+	if problem != "" {
+		return usageError(session, g.commands["who"], problem)
+	}
+
+	var players []*Session
+	for _, s := range g.sessions {
+		if s.loggedIn() && filter.matches(session, s) {
+			players = append(players, s)
+		}
+	}
+	sortPlayers(players, filter.sortBy)
+
+	if len(players) == 0 {
+		return []OutputEvent{{SessionID: session.ID, Message: "No players match."}}
+	}
+	lines := []string{
+		fmt.Sprintf("Players online: %d", len(players)),
+		fmt.Sprintf("%-20s %-12s %-15s %5s  %s", "Name", "Class", "Room", "Idle", "Title"),
+	}
+	for _, s := range players {
+		var notes []string
+		if s.Account.Title != "" {
+			notes = append(notes, s.Account.Title)
+		}
+		if s.afk {
+			notes = append(notes, "[AFK]")
+		}
+		if s.isStaff() {
+			notes = append(notes, "[staff]")
 		}
+		if s.group != nil {
+			notes = append(notes, fmt.Sprintf("[%s's group]", s.group.Leader.Name))
+		}
+		line := fmt.Sprintf("%-20s %-12s %-15s %5s  %s",
+			s.Name, s.Account.class(), s.Room.Name, formatIdle(g.clock.Now().Sub(s.lastInput)), strings.Join(notes, " "))
+		lines = append(lines, strings.TrimRight(line, " "))
 	}
+	return []OutputEvent{{SessionID: session.ID, Message: strings.Join(lines, "\n")}}
+}
+
+func parseWhoFilter(params string) (whoFilter, string) {
+	filter := whoFilter{sortBy: "name"}
+	words := strings.Fields(strings.ToLower(params))
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		next := ""
+		if i+1 < len(words) {
+			next = words[i+1]
+		}
+		switch word {
+		case "here":
+			filter.here = true
+		case "group":
+			filter.group = true
+		case "staff":
+			filter.staff = true
+		case "afk":
+			filter.afk = true
+		case "class", "room", "sort":
+			if next == "" {
+				return filter, fmt.Sprintf("%s needs a value.", word)
+			}
+			switch word {
+			case "class":
+				filter.class = next
+			case "room":
+				filter.room = next
+			default:
+				if next != "name" && next != "idle" {
+					return filter, "You can sort by name or idle."
+				}
+				filter.sortBy = next
+			}
+			i++
+		default:
+			filter.name = word
+		}
+	}
+	return filter, ""
+}
+
+// matches reports whether player passes the filter as seen by viewer.
+func (f whoFilter) matches(viewer, player *Session) bool {
+	switch {
+	case f.here && player.Room != viewer.Room,
+		f.group && (viewer.group == nil || player.group != viewer.group),
+		f.staff && !player.isStaff(),
+		f.afk && !player.afk,
+		f.class != "" && !strings.HasPrefix(strings.ToLower(player.Account.class()), f.class),
+		f.room != "" && !strings.HasPrefix(strings.ToLower(player.Room.Name), f.room),
+		f.name != "" && !strings.HasPrefix(strings.ToLower(player.Name), f.name):
+		return false
+	}
+	return true
+}
+
+func sortPlayers(players []*Session, by string) {
+	sort.Slice(players, func(i, j int) bool {
+		a, b := players[i], players[j]
+		switch by {
+		case "idle":
+			if !a.lastInput.Equal(b.lastInput) {
+				return a.lastInput.After(b.lastInput)
+			}
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	})
+}
 
-	userList := []string{}
-	for _, s := range session.Room.Sessions {
-		userList = append(userList, s.Name)
+// formatIdle renders an idle time compactly, such as "42s", "5m" or "3h".
+func formatIdle(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	default:
+		return fmt.Sprintf("%dh", int(d.Hours()))
 	}
-	return []OutputEvent{{
-		SessionID: session.ID,
-		Message:   fmt.Sprintf("Users in this room: %s", strings.Join(userList, ", ")),
-	}}
 }
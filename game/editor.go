@@ -0,0 +1,55 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+)
+
+const maxEditorLines = 100 // longest text the line editor accepts
+
+// lineEditor collects multi-line text from a player. While it is open the
+// session is in StateEditing and every line goes to the editor instead of
+// being parsed as a command or chat.
+type lineEditor struct {
+	lines []string
+	done  func(g *Game, session *Session, text string) []OutputEvent
+}
+
+// startEditor opens a line editor for the session. done runs with the text
+// once the player finishes it; it does not run if they abort.
+func (g *Game) startEditor(session *Session, title string, done func(g *Game, session *Session, text string) []OutputEvent) []OutputEvent {
+	session.editor = &lineEditor{done: done}
+	session.State = StateEditing
+	return []OutputEvent{{
+		SessionID: session.ID,
+		Message:   fmt.Sprintf("%s\nEnter your text. End with a line containing only \".\", or \"~q\" to abort.", title),
+	}}
+}
+
+// handleEditorInput adds a line to the session's open editor, or closes the
+// editor when the player finishes or aborts.
+func (g *Game) handleEditorInput(session *Session, line string) []OutputEvent {
+	editor := session.editor
+	switch strings.TrimSpace(line) {
+	case ".":
+		g.closeEditor(session)
+		if len(editor.lines) == 0 {
+			return []OutputEvent{{SessionID: session.ID, Message: "Nothing written, discarded."}}
+		}
+		return editor.done(g, session, strings.Join(editor.lines, "\n"))
+	case "~q":
+		g.closeEditor(session)
+		return []OutputEvent{{SessionID: session.ID, Message: "Aborted."}}
+	}
+
+	if len(editor.lines) >= maxEditorLines {
+		return []OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Your text cannot be longer than %d lines. End it with \".\" or abort with \"~q\".", maxEditorLines)}}
+	}
+	editor.lines = append(editor.lines, line)
+	return nil
+}
+
+func (g *Game) closeEditor(session *Session) {
+	session.editor = nil
+	session.State = StatePlaying
+}
@@ -0,0 +1,140 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// MaxLineLength is the longest line of input the game accepts, in bytes.
+// Transports should stop reading a line once it is longer, and pass on
+// what they kept so the player is told.
+const MaxLineLength = 4096
+
+const (
+	// A session may send up to defaultInputBurst lines at once, and
+	// defaultInputRate a second after that.
+	defaultInputRate  = 10
+	defaultInputBurst = 20
+
+	// A session that keeps sending too fast within warningPeriod of being
+	// warned has its input ignored for suspensionPeriod.
+	warningPeriod    = time.Minute
+	suspensionPeriod = 30 * time.Second
+
+	// A player may say the same thing maxRepeats times in a row within
+	// repeatPeriod of the last time before they are told to stop.
+	maxRepeats   = 3
+	repeatPeriod = time.Minute
+)
+
+// inputLimiter is a token bucket limiting how fast a session may send
+// input. It is only used by the goroutine submitting the session's input.
+type inputLimiter struct {
+	tokens         float64
+	updated        time.Time // when tokens was last topped up
+	warned         time.Time // when the session was last told to slow down
+	suspendedUntil time.Time
+}
+
+// allow reports whether a line arriving at now may be handled, and the
+// message to send the session if it may not. A session over the limit is
+// warned first and has its input suspended if it goes on.
+func (l *inputLimiter) allow(now time.Time, rate float64, burst int) (bool, string) {
+	if now.Before(l.suspendedUntil) {
+		return false, ""
+	}
+	if l.updated.IsZero() {
+		l.tokens = float64(burst)
+	} else {
+		l.tokens = min(float64(burst), l.tokens+now.Sub(l.updated).Seconds()*rate)
+	}
+	l.updated = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, ""
+	}
+	if l.warned.IsZero() || now.Sub(l.warned) > warningPeriod {
+		l.warned = now
+		return false, "You are typing too fast, so that was ignored. Slow down, or your input will be suspended."
+	}
+	l.warned = time.Time{}
+	l.suspendedUntil = now.Add(suspensionPeriod)
+	return false, fmt.Sprintf("You kept typing too fast, so your input is ignored for %d seconds.", int(suspensionPeriod.Seconds()))
+}
+
+// SubmitInput passes a line of input from a session to the game. Lines
+// that are too long, or that come faster than the session may send them,
+// are turned down here, before they take up room in the input queue every
+// session shares. Transports call it from the goroutine reading the
+// session's connection, once the session has been created.
+func (g *Game) SubmitInput(event InputEvent) {
+	if g.admitInput(&event) {
+		g.inputChannel <- event
+	}
+}
+
+// admitInput records a line of input and reports whether the game should
+// handle it, telling the session why not if it should not. Lines from a
+// session that has not logged in may be passwords, so they are recorded
+// once they are handled instead.
+func (g *Game) admitInput(event *InputEvent) bool {
+	g.mu.RLock()
+	session, exists := g.sessions[event.SessionID]
+	if exists && session.loggedIn() {
+		g.recordLine(session, *event)
+	} else {
+		event.recordLater = true
+	}
+	g.mu.RUnlock()
+	if !exists {
+		// The session has already left the game, which drops the line
+		return true
+	}
+	if len(event.Input) > MaxLineLength {
+		g.recordRejected(*event)
+		g.notify(session, fmt.Sprintf("That line was too long, so it was ignored. Lines can be up to %d characters.", MaxLineLength))
+		return false
+	}
+	if g.inputRate <= 0 {
+		return true
+	}
+	allowed, message := session.limiter.allow(g.clock.Now(), g.inputRate, g.inputBurst)
+	if !allowed {
+		g.recordRejected(*event)
+		g.metrics.droppedInput.Inc()
+		if message != "" {
+			withSession(gameLog, session).Warn("Session is sending input too fast", "suspended", g.clock.Now().Before(session.limiter.suspendedUntil))
+			g.notify(session, message)
+		}
+	}
+	return allowed
+}
+
+// notify sends the session a message from outside the actors, by way of
+// the actor of its room.
+func (g *Game) notify(session *Session, message string) {
+	session.post(job{run: func() []OutputEvent {
+		return []OutputEvent{{SessionID: session.ID, Message: message}}
+	}})
+}
+
+// chatRefusal returns the message telling a player they cannot say text,
+// because they are muted or keep saying the same thing, or nil if they
+// can.
+func (g *Game) chatRefusal(session *Session, text string) []OutputEvent {
+	if refusal := g.mutedRefusal(session); refusal != nil {
+		return refusal
+	}
+	now := g.clock.Now()
+	text = strings.ToLower(strings.TrimSpace(text))
+	if text != session.lastChat || now.Sub(session.lastChatAt) > repeatPeriod {
+		session.lastChat, session.chatRepeats = text, 0
+	}
+	session.lastChatAt = now
+	session.chatRepeats++
+	if session.chatRepeats > maxRepeats {
+		return []OutputEvent{{SessionID: session.ID, Message: "You have said that too many times. Say something else."}}
+	}
+	return nil
+}
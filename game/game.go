@@ -2,153 +2,495 @@ package game
 
 import (
 	"fmt"
-	"log"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Game struct {
-	sessions     map[string]*Session
-	usernames    map[string]*Session // maps username to Session
-	lobby        *Room
-	mu           sync.Mutex
-	inputChannel chan InputEvent
-	commands 	 map[string]command
+	sessions      map[string]*Session
+	usernames     map[string]*Session // maps username to Session
+	lobby         *Room
+	rooms         map[string]*Room // maps lowercased room name to Room
+	mu            sync.RWMutex     // the world lock; see actor
+	inputChannel  chan InputEvent
+	commands      map[string]*Command // maps command names and aliases to commands
+	bareCommands  bool                // parse input without a leading "/" as a command
+	dataDir       string              // where persistent state is kept, empty to keep it in memory
+	accounts      *accountStore
+	channels      map[string]*Channel   // maps channel name to Channel
+	socials       map[string]Social     // maps social name to Social
+	grants        map[Role][]Permission // permissions each role adds to those below it
+	owner         string                // name of the player who always has the owner role
+	ownerPassword string                // what the owner logs in with until they set a password
+	bans          []Ban
+	loginFailures map[string]*loginFailures // recent wrong passwords by account and by address
+	auditLog      *auditLog
+	logPrivate    bool          // write the text of private messages to the server log
+	done          chan struct{} // closed once the game has shut down
+	countdown     Timer         // pending /shutdown
+	clock         Clock         // tells the time players see
+	random        *random       // draws the random numbers players see
+	metrics       *gameMetrics
+	recorder      *recorder // records every session's input and output, if set
+	inputRate     float64   // lines a second each session may send, 0 for no limit
+	inputBurst    int       // lines each session may send at once
+
+	lastHandled atomic.Int64 // when the last input event was handled, in Unix nanoseconds
+	jobs        atomic.Int64 // jobs posted to actors and not yet finished
+
+	actorsMu sync.Mutex
+	actors   map[*actor]bool // every running actor, for health checks
+
+	stallTimeout time.Duration // how long a job may run before the game is unhealthy
 }
 
 type Session struct {
 	ID            string
 	Name          string
+	Account       *Account
 	Room          *Room
+	State         LoginState
+	Permissions   map[Permission]bool
 	OutputChannel chan OutputEvent
+	replyTo       string   // name of the last player to whisper to this session
+	tells         []string // recent whispers sent and received, for /tells
+	editor        *lineEditor
+	following     *Session // who this session moves along with
+	group         *Group
+	groupInvite   *Session // player who invited this session to their group
+	lastInput     time.Time
+	afk           bool
+	afkMessage    string // shown to players who whisper while away
+	outbox        *outbox
+	home          atomic.Pointer[Room] // Room, for finding the session's actor from any goroutine
+	input         inputQueue
+	limiter       inputLimiter // how fast the session may send input
+	lastChat      string       // what the session last said, lowercased
+	lastChatAt    time.Time
+	chatRepeats   int // times in a row the session has said lastChat
 }
 
 type Room struct {
-	Name     string
-	Sessions map[string]*Session
+	Name        string
+	Description string
+	Sessions    map[string]*Session
+	Exits       map[string]*Room // maps direction to the room it leads to
+	Board       *Board
+	actor       *actor
 }
 
+// InputKind tells what an InputEvent is about.
+type InputKind int
+
+const (
+	InputLine       InputKind = iota // the player typed Input
+	InputConnect                     // a new connection; ResponseChan is told whether its session was created
+	InputDisconnect                  // the connection is gone
+)
+
 type InputEvent struct {
 	SessionID    string
+	Kind         InputKind
 	Input        string
 	ResponseChan chan bool
+
+	recordLater bool   // the line is recorded once handled, when it is known whether it is a password
+	checked     string // for a password replayed from a recording, how its check went
 }
 
 type OutputEvent struct {
 	SessionID string
 	Message   string
 	Quit      bool
+	From      string // name of the player who caused the message, if any
 }
 
-func NewGame() *Game {
+func NewGame(options ...Option) *Game {
 	g := &Game{
 		sessions:     make(map[string]*Session),
 		usernames:    make(map[string]*Session),
-		lobby:        &Room{Name: "Lobby", Sessions: make(map[string]*Session)},
 		inputChannel: make(chan InputEvent, 100),
-		commands: map[string]command{
-			"whisper": handleWhisper,
-			"who":     handleListUsersInRoom,
-			"help":    handleHelp,
-			"quit":    handleQuit,
-		},
+		commands:     make(map[string]*Command),
+		channels:     make(map[string]*Channel),
+		actors:       make(map[*actor]bool),
+		grants:       make(map[Role][]Permission),
+		done:         make(chan struct{}),
+		clock:        realClock{},
+		random:       newRandom(defaultRandomSource()),
+		inputRate:    defaultInputRate,
+		inputBurst:   defaultInputBurst,
+		stallTimeout: defaultStallTimeout,
+	}
+	g.metrics = g.newMetrics()
+	g.lastHandled.Store(time.Now().UnixNano())
+	rooms, lobby, err := buildWorld(defaultWorld)
+	if err != nil {
+		panic(err)
+	}
+	g.rooms, g.lobby = rooms, lobby
+	for _, room := range g.rooms {
+		room.actor = newActor(g, room.Name)
+	}
+	for role, permissions := range defaultRolePermissions {
+		g.grants[role] = slices.Clone(permissions)
 	}
+	for _, name := range defaultChannels {
+		g.channels[name] = g.newChannel(name, false, "")
+	}
+	for _, cmd := range append([]Command{
+		sayCommand,
+		emoteCommand,
+		whisperCommand,
+		replyCommand,
+		tellsCommand,
+		channelCommand,
+		mailCommand,
+		boardCommand,
+		groupCommand,
+		gtellCommand,
+		lookCommand,
+		goCommand,
+		followCommand,
+		unfollowCommand,
+		whoCommand,
+		fingerCommand,
+		afkCommand,
+		titleCommand,
+		helpCommand,
+		quitCommand,
+		aliasCommand,
+		unaliasCommand,
+		passwordCommand,
+		ignoreCommand,
+		unignoreCommand,
+		roleCommand,
+		kickCommand,
+		banCommand,
+		unbanCommand,
+		bansCommand,
+		muteCommand,
+		unmuteCommand,
+		gotoCommand,
+		transferCommand,
+		forceCommand,
+		broadcastCommand,
+		shutdownCommand,
+		auditCommand,
+		loglevelCommand,
+	}, directionCommands()...) {
+		if err := g.RegisterCommand(cmd); err != nil {
+			panic(err)
+		}
+	}
+	socials, err := parseSocials(defaultSocials)
+	if err != nil {
+		panic(err)
+	}
+	g.socials = make(map[string]Social)
+	for _, social := range socials {
+		g.socials[social.Name] = social
+	}
+	for _, option := range options {
+		option(g)
+	}
+	g.accounts = newAccountStore(g.dataDir, g.clock)
+	if g.auditLog, err = newAuditLog(g.dataDir); err != nil {
+		storageLog.Error("Failed to load audit log", "err", err)
+	}
+	if err := g.loadBoards(); err != nil {
+		storageLog.Error("Failed to load boards", "err", err)
+	}
+	if err := g.loadBans(); err != nil {
+		storageLog.Error("Failed to load bans", "err", err)
+	}
+	g.registerSocials()
 	go g.processEvents()
 	return g
 }
 
+// processEvents hands each input event to the actor of the session's room.
 func (g *Game) processEvents() {
 	for input := range g.inputChannel {
-		g.handleInput(input)
+		g.routeInput(input)
+		g.lastHandled.Store(time.Now().UnixNano())
+		g.metrics.inputEvents.Inc()
 	}
 }
-func (g *Game) handleInput(event InputEvent) {
-	var messagesToSend []OutputEvent
 
-	g.mu.Lock()
+func (g *Game) routeInput(event InputEvent) {
+	if event.Kind == InputConnect {
+		g.recordInput(event)
+		g.connect(event)
+		return
+	}
+
+	g.mu.RLock()
 	session, exists := g.sessions[event.SessionID]
+	g.mu.RUnlock()
 	if !exists {
-		// New session
-		session = &Session{
-			ID:            event.SessionID,
-			Name:          "",
-			Room:          g.lobby,
-			OutputChannel: make(chan OutputEvent, 100),
+		// Input from a connection that has already left the game
+		return
+	}
+	if event.Kind == InputDisconnect {
+		// Lines are recorded as they are submitted
+		g.recordInput(event)
+	}
+	session.input.push(event)
+	session.post(job{
+		exclusive: func() bool {
+			next := session.input.peek()
+			return next.Kind != InputLine || !g.runsLocally(session, next.Input)
+		},
+		run: func() []OutputEvent {
+			return g.handleInput(session, session.input.pop())
+		},
+	})
+}
+
+// connect creates the session for a new connection.
+func (g *Game) connect(event InputEvent) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if _, exists := g.sessions[event.SessionID]; exists {
+		event.ResponseChan <- false
+		return
+	}
+	outbox := newOutbox(g.metrics.queuedOutput)
+	session := &Session{
+		ID:            event.SessionID,
+		Name:          "",
+		State:         StateNaming,
+		Permissions:   make(map[Permission]bool),
+		OutputChannel: outbox.out,
+		outbox:        outbox,
+	}
+	g.sessions[event.SessionID] = session
+	g.place(session, g.lobby)
+	g.deliver(session, OutputEvent{
+		SessionID: event.SessionID,
+		Message:   "Who are you?",
+	})
+
+	// Send confirmation that the session was created
+	event.ResponseChan <- true
+}
+
+// runsLocally reports whether input from the session only touches the
+// session and its room, so it can be handled alongside other rooms.
+// Players choosing a name or writing in the line editor always need the
+// world to themselves.
+func (g *Game) runsLocally(session *Session, input string) bool {
+	if session.State != StatePlaying {
+		return false
+	}
+	input = strings.TrimSpace(input)
+	if !g.isCommand(session, input) {
+		return true
+	}
+	lines, problem := g.expandAliases(session, strings.TrimPrefix(input, "/"), 0)
+	if problem != "" {
+		return true
+	}
+	for _, line := range lines {
+		name, _ := g.splitCommandLine(line)
+		if command, _ := g.findCommand(session, name); command != nil && !command.Local {
+			return false
+		}
+	}
+	return true
+}
+
+// handleInput handles an input event from a session. It runs on the
+// actor of the session's room, with the world lock held exclusively
+// unless runsLocally allows otherwise.
+func (g *Game) handleInput(session *Session, event InputEvent) []OutputEvent {
+	var messagesToSend []OutputEvent
+	if event.Kind == InputDisconnect {
+		return g.endSession(session, messagesToSend)
+	}
+	if event.recordLater && session.State != StatePassword && session.State != StateChecking {
+		// Passwords are recorded once they have been checked, and lines
+		// sent while one is checked are ignored
+		g.recordLine(session, event)
+	}
+	if session.State != StateEditing {
+		// Only text written in the editor keeps its blank lines and indentation
+		event.Input = strings.TrimSpace(event.Input)
+		if event.Input == "" {
+			return nil
 		}
-		g.sessions[event.SessionID] = session
-		g.lobby.Sessions[event.SessionID] = session
+	}
 
-		messagesToSend = append(messagesToSend, OutputEvent{
-			SessionID: event.SessionID,
-			Message:   "Who are you?",
-		})
+	session.lastInput = g.clock.Now()
+	if session.loggedIn() && !isAfkCommand(event.Input) {
+		messagesToSend = append(messagesToSend, clearAfk(session)...)
+	}
 
-		// Send confirmation that the session was created
-		if event.ResponseChan != nil {
-			event.ResponseChan <- true
+	if session.State == StateEditing {
+		messagesToSend = append(messagesToSend, g.handleEditorInput(session, event.Input)...)
+	} else if session.State == StatePassword {
+		messagesToSend = append(messagesToSend, g.handleLoginPassword(session, event)...)
+	} else if session.State == StateChecking {
+		// Nothing is read until the password has been checked
+	} else if g.isCommand(session, event.Input) {
+		output, quit := g.handleCommand(session, strings.TrimPrefix(event.Input, "/"))
+		messagesToSend = append(messagesToSend, output...)
+		if quit {
+			messagesToSend = g.endSession(session, messagesToSend)
+		}
+	} else if session.State == StateNaming {
+		// Check if the input is a valid username
+		if !validName(event.Input) {
+			messagesToSend = append(messagesToSend, OutputEvent{
+				SessionID: event.SessionID,
+				Message:   "Names must be 2 to 20 letters or digits. Please enter a different username.",
+			})
+		} else if _, exists := g.usernames[strings.ToLower(event.Input)]; exists {
+			g.audit(session, "login-refused", event.Input, "name in use")
+			messagesToSend = append(messagesToSend, OutputEvent{
+				SessionID: event.SessionID,
+				Message:   fmt.Sprintf("Username '%s' is already taken. Please enter a different username.", event.Input),
+			})
+		} else if account, err := g.accounts.load(event.Input); err != nil {
+			storageLog.Error("Failed to load account", "account", event.Input, "err", err)
+			messagesToSend = append(messagesToSend, OutputEvent{
+				SessionID: event.SessionID,
+				Message:   "Your account could not be loaded. Please try again later.",
+			})
+		} else if ban := g.findBan(event.Input, account.bannableIP(sessionIP(session))); ban != nil {
+			g.audit(session, "login-refused", event.Input, "banned")
+			messagesToSend = append(messagesToSend, OutputEvent{
+				SessionID: event.SessionID,
+				Message:   ban.describe(),
+				Quit:      true,
+			})
+			messagesToSend = g.endSession(session, messagesToSend)
+		} else if account.Password != "" || (g.isOwner(account) && g.ownerPassword != "") {
+			// The player must prove the account is theirs
+			session.Name = event.Input
+			session.Account = account
+			session.State = StatePassword
+			messagesToSend = append(messagesToSend, OutputEvent{
+				SessionID: event.SessionID,
+				Message:   "Password:",
+			})
+		} else if account.role() != RolePlayer || g.isOwner(account) {
+			g.audit(session, "login-refused", event.Input, "no password")
+			messagesToSend = append(messagesToSend, OutputEvent{
+				SessionID: event.SessionID,
+				Message:   fmt.Sprintf("%s is a staff account without a password, so nobody can log in as %s. Please enter a different username.", account.Name, account.Name),
+			})
+		} else {
+			session.Name = event.Input
+			session.Account = account
+			messagesToSend = append(messagesToSend, g.login(session)...)
 		}
 	} else {
+		// Treat as chat and broadcast to the room
+		messagesToSend = append(messagesToSend, g.say(session, event.Input)...)
+	}
+	return messagesToSend
+}
 
-		if session.Name == "" {
-			// Check if the input is a valid username
-			if _, exists := g.usernames[event.Input]; exists {
-				messagesToSend = append(messagesToSend, OutputEvent{
-					SessionID: event.SessionID,
-					Message:   fmt.Sprintf("Username '%s' is already taken. Please enter a different username.", event.Input),
-				})
-			} else {
-				// Set the username
-				session.Name = event.Input
-				g.usernames[event.Input] = session
-				messagesToSend = append(messagesToSend, OutputEvent{
-					SessionID: event.SessionID,
-					Message:   fmt.Sprintf("Welcome, %s!", session.Name),
-				})
-				messagesToSend = append(messagesToSend, g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s has joined the room.", session.Name), "")...)
-			}
-		} else if strings.HasPrefix(event.Input, "/") { // Check if the input is a command or chat
-			output, quit := g.handleCommand(session, event.Input[1:])
-			messagesToSend = append(messagesToSend, output...)
-			if quit {
-				go func() {
-					delete(g.sessions, session.ID)
-					delete(session.Room.Sessions, session.ID)
-					close(session.OutputChannel)
-				}()
-				messagesToSend = append(messagesToSend, g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s has left the room.", session.Name), "")...)
-			}
-		} else {
-			// Treat as chat and broadcast to the room
-			messagesToSend = append(messagesToSend, g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s says: %s", session.Name, event.Input), "")...)
+// endSession removes a session that is going away from the game. The
+// events in messages that are for the session are its last: they are
+// delivered now and the session's output is closed after them. The other
+// events are returned along with the ones telling the room it left.
+func (g *Game) endSession(session *Session, messages []OutputEvent) []OutputEvent {
+	if g.sessions[session.ID] != session {
+		return messages
+	}
+
+	var others []OutputEvent
+	for _, event := range messages {
+		if event.SessionID != session.ID {
+			others = append(others, event)
+		} else if !g.isIgnoring(session, event) {
+			g.record(session.ID, RecordOutput, event.Message)
+			session.outbox.push(event)
 		}
 	}
-	g.mu.Unlock()
+	session.outbox.close()
+
+	g.leaveAllChannels(session)
+	others = append(others, g.leaveGroupAndFollowers(session)...)
+	delete(g.sessions, session.ID)
+	delete(session.Room.Sessions, session.ID)
+	if !session.loggedIn() {
+		return others
+	}
+
+	g.audit(session, "logout", "", "")
+	delete(g.usernames, strings.ToLower(session.Name))
+	session.Account.LastSeen = g.clock.Now()
+	if err := g.accounts.save(session.Account); err != nil {
+		withSession(storageLog, session).Error("Failed to save account", "err", err)
+	}
+	return append(others, g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s has left the room.", session.Name))...)
+}
 
-	// Send collected messages after releasing the lock
-	for _, msg := range messagesToSend {
-		g.sendOutput(msg)
+// isCommand reports whether input should be dispatched as a command rather
+// than treated as a name or chat.
+func (g *Game) isCommand(session *Session, input string) bool {
+	if strings.HasPrefix(input, "/") {
+		return true
 	}
+	return g.bareCommands && session.State == StatePlaying
 }
 
+// handleCommand expands the player's aliases in inputString and runs the
+// resulting commands in order, stopping early if one of them quits.
 func (g *Game) handleCommand(session *Session, inputString string) ([]OutputEvent, bool) {
+	lines, problem := g.expandAliases(session, inputString, 0)
+	if problem != "" {
+		return []OutputEvent{{
+			SessionID: session.ID,
+			Message:   problem,
+		}}, false
+	}
 
-	parts := strings.Split(inputString, " ")
-	cmd := parts[0]
-	params := strings.Join(parts[1:], " ")
 	var outputEvents []OutputEvent
+	for _, line := range lines {
+		output, quit := g.runCommand(session, line)
+		outputEvents = append(outputEvents, output...)
+		if quit {
+			return outputEvents, true
+		}
+	}
+	return outputEvents, false
+}
+
+func (g *Game) runCommand(session *Session, inputString string) ([]OutputEvent, bool) {
 
-	if command, exists := g.commands[cmd]; exists {
-		outputEvents = command(g, session, params, false)
+	cmd, params := g.splitCommandLine(inputString)
+	var outputEvents []OutputEvent
+
+	if command, problem := g.findCommand(session, cmd); command != nil {
+		if args, err := parseArgs(command.Args, params); err != nil {
+			outputEvents = usageError(session, command, err.Error())
+		} else {
+			if command.Permission != "" {
+				g.audit(session, "command", "", "/"+strings.TrimSpace(inputString))
+			}
+			start := time.Now()
+			outputEvents = command.Handler(g, session, args)
+			g.metrics.commandDuration.Observe(time.Since(start).Seconds(), command.Name)
+		}
+	} else if channel := g.findJoinedChannel(session, cmd); channel != nil {
+		outputEvents = g.speakOnChannel(session, channel, params)
 	} else {
 		outputEvents = []OutputEvent{{
 			SessionID: session.ID,
-			Message:   fmt.Sprintf("Unknown command: %s", cmd),
+			Message:   problem,
 		}}
 	}
 
 	var quit bool
 	for _, output := range outputEvents {
-		if output.Quit {
+		if output.Quit && output.SessionID == session.ID {
 			quit = true
 			break
 		}
@@ -157,47 +499,58 @@ func (g *Game) handleCommand(session *Session, inputString string) ([]OutputEven
 	return outputEvents, quit
 }
 
-func (g *Game) collectBroadcastMessages(room *Room, message string, excludeID string) []OutputEvent {
+// collectBroadcastMessages returns an event with message for everyone in the
+// room except the sessions in excludeIDs.
+func (g *Game) collectBroadcastMessages(room *Room, message string, excludeIDs ...string) []OutputEvent {
 	var messages []OutputEvent
 	for sessionID := range room.Sessions {
-		if sessionID != excludeID {
+		if !slices.Contains(excludeIDs, sessionID) {
 			messages = append(messages, OutputEvent{SessionID: sessionID, Message: message})
 		}
 	}
 	return messages
 }
 
-func (g *Game) sendOutput(event OutputEvent) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
-	if event.SessionID == "" {
-		// Broadcast to all sessions
-		for _, session := range g.sessions {
-			select {
-			case session.OutputChannel <- event:
-			default:
-				log.Printf("Output channel full for user %s, discarding message: %s", session.Name, event.Message)
-			}
-		}
-	} else {
-		// Send to specific session
-		if session, exists := g.sessions[event.SessionID]; exists {
-			select {
-			case session.OutputChannel <- event:
-			default:
-				log.Printf("Output channel full for user %s, discarding message: %s", session.Name, event.Message)
+// send delivers events to the sessions they are for. An event without a
+// session goes to everyone. The world lock must be held.
+func (g *Game) send(events []OutputEvent) {
+	for _, event := range events {
+		if event.SessionID == "" {
+			for _, session := range g.sessions {
+				g.deliver(session, event)
 			}
+		} else if session, exists := g.sessions[event.SessionID]; exists && !g.isIgnoring(session, event) {
+			g.deliver(session, event)
 		}
 	}
 }
 
+// deliver queues an event for a session. A session that cannot keep up
+// with its output is disconnected.
+func (g *Game) deliver(session *Session, event OutputEvent) {
+	g.record(session.ID, RecordOutput, event.Message)
+	if err := session.outbox.push(event); err == nil {
+		return
+	}
+	dropped := session.outbox.abandon("You are being disconnected because your connection cannot keep up.") + 1
+	withSession(gameLog, session).Warn("Disconnecting slow session", "dropped", dropped)
+	g.metrics.droppedOutput.Add(float64(dropped))
+	g.metrics.slowDisconnects.Inc()
+	session.post(job{exclusive: always, run: func() []OutputEvent {
+		return g.endSession(session, nil)
+	}})
+}
+
+// GetInputChannel returns the channel that connections and disconnections
+// are sent on. Lines of input go to SubmitInput instead, which checks them
+// first.
 func (g *Game) GetInputChannel() chan<- InputEvent {
 	return g.inputChannel
 }
 
 func (g *Game) GetOutputChannel(sessionID string) (<-chan OutputEvent, bool) {
-	g.mu.Lock()
-	defer g.mu.Unlock()
+	g.mu.RLock()
+	defer g.mu.RUnlock()
 
 	session, exists := g.sessions[sessionID]
 	if !exists {
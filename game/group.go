@@ -0,0 +1,82 @@
+package game
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Group is a party of players who travel and talk together.
+type Group struct {
+	Leader  *Session
+	Members []*Session // in the order they joined, starting with the leader
+}
+
+// broadcast returns an event with message for every member.
+func (gr *Group) broadcast(message string) []OutputEvent {
+	var messages []OutputEvent
+	for _, member := range gr.Members {
+		messages = append(messages, OutputEvent{SessionID: member.ID, Message: "[group] " + message})
+	}
+	return messages
+}
+
+// removeFromGroup takes the session out of its group, handing leadership
+// to the next member or disbanding the group if only one would remain.
+func (g *Game) removeFromGroup(session *Session, message string) []OutputEvent {
+	group := session.group
+	if group == nil {
+		return nil
+	}
+	messages := group.broadcast(message)
+	group.Members = slices.DeleteFunc(group.Members, func(member *Session) bool { return member == session })
+	session.group = nil
+	if session.following == group.Leader {
+		session.following = nil
+	}
+
+	if len(group.Members) < 2 {
+		return append(messages, g.disbandGroup(group)...)
+	}
+	if group.Leader == session {
+		group.Leader = group.Members[0]
+		if group.Leader.following == session {
+			group.Leader.following = nil
+		}
+		for _, member := range group.Members[1:] {
+			if member.following == session {
+				member.following = group.Leader
+			}
+		}
+		messages = append(messages, group.broadcast(fmt.Sprintf("%s now leads the group.", group.Leader.Name))...)
+	}
+	return messages
+}
+
+func (g *Game) disbandGroup(group *Group) []OutputEvent {
+	messages := group.broadcast("The group has been disbanded.")
+	for _, member := range group.Members {
+		member.group = nil
+		if member.following == group.Leader {
+			member.following = nil
+		}
+	}
+	group.Members = nil
+	return messages
+}
+
+// leaveGroupAndFollowers detaches a session that is going away from its
+// group and from everyone following it.
+func (g *Game) leaveGroupAndFollowers(session *Session) []OutputEvent {
+	messages := g.removeFromGroup(session, fmt.Sprintf("%s has left the game.", session.Name))
+	for _, s := range g.sessions {
+		if s.following == session {
+			s.following = nil
+			messages = append(messages, OutputEvent{SessionID: s.ID, Message: fmt.Sprintf("You stop following %s.", session.Name)})
+		}
+		if s.groupInvite == session {
+			s.groupInvite = nil
+		}
+	}
+	session.following = nil
+	return messages
+}
@@ -0,0 +1,28 @@
+package game
+
+import (
+	"log/slog"
+
+	"mud/logging"
+)
+
+// Loggers for the parts of the game, so each can be made more or less
+// verbose with /loglevel.
+var (
+	gameLog    = logging.For("game")
+	storageLog = logging.For("storage")
+	chatLog    = logging.For("chat")
+	adminLog   = logging.For("admin")
+)
+
+// withSession adds the session, player and room to everything logged.
+func withSession(logger *slog.Logger, session *Session) *slog.Logger {
+	logger = logger.With("session", session.ID)
+	if session.Name != "" {
+		logger = logger.With("player", session.Name)
+	}
+	if session.Room != nil {
+		logger = logger.With("room", session.Room.Name)
+	}
+	return logger
+}
@@ -0,0 +1,161 @@
+package game
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// isOwner reports whether the account belongs to the server's owner.
+func (g *Game) isOwner(account *Account) bool {
+	return g.owner != "" && strings.EqualFold(account.Name, g.owner)
+}
+
+const (
+	// After maxLoginFailures wrong passwords for one account, or from one
+	// address, within loginLockout of each other, logging in to that
+	// account or from that address is refused for loginLockout.
+	maxLoginFailures = 5
+	loginLockout     = 5 * time.Minute
+)
+
+// loginFailures counts the recent wrong passwords for an account or from an
+// address.
+type loginFailures struct {
+	count       int
+	last        time.Time // when the last wrong password was given
+	lockedUntil time.Time
+}
+
+// loginLockedOut returns why the session may not try a password for the
+// account it named right now, or "" if it may.
+func (g *Game) loginLockedOut(session *Session) string {
+	now := g.clock.Now()
+	if f := g.loginFailures["account "+strings.ToLower(session.Name)]; f != nil && now.Before(f.lockedUntil) {
+		return fmt.Sprintf("Too many wrong passwords have been given for %s. Please try again later.", session.Name)
+	}
+	if f := g.loginFailures["ip "+sessionIP(session)]; f != nil && now.Before(f.lockedUntil) {
+		return "Too many wrong passwords have been given from your address. Please try again later."
+	}
+	return ""
+}
+
+// failLogin counts a wrong password against the account the session named
+// and against its address.
+func (g *Game) failLogin(session *Session) {
+	now := g.clock.Now()
+	if g.loginFailures == nil {
+		g.loginFailures = make(map[string]*loginFailures)
+	}
+	for key, f := range g.loginFailures {
+		if now.Sub(f.last) > loginLockout && now.After(f.lockedUntil) {
+			delete(g.loginFailures, key)
+		}
+	}
+	for _, key := range []string{"account " + strings.ToLower(session.Name), "ip " + sessionIP(session)} {
+		f := g.loginFailures[key]
+		if f == nil {
+			f = &loginFailures{}
+			g.loginFailures[key] = f
+		}
+		f.count++
+		f.last = now
+		if f.count >= maxLoginFailures {
+			f.count = 0
+			f.lockedUntil = now.Add(loginLockout)
+		}
+	}
+}
+
+// checkAccountPassword reports whether password opens an account with the
+// given password hash. The owner may use the owner password until they
+// set one of their own. It is slow, so it is run away from the world lock.
+func (g *Game) checkAccountPassword(hash string, owner bool, password string) bool {
+	if hash != "" {
+		return checkPassword(hash, password)
+	}
+	return owner && g.ownerPassword != "" &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(g.ownerPassword)) == 1
+}
+
+// handleLoginPassword starts checking the password given for the account
+// the session named. The session logs in once it has been checked.
+func (g *Game) handleLoginPassword(session *Session, event InputEvent) []OutputEvent {
+	if reason := g.loginLockedOut(session); reason != "" {
+		g.recordPassword(session, false)
+		name := session.Name
+		session.Name, session.Account, session.State = "", nil, StateNaming
+		g.audit(session, "login-refused", name, "locked out")
+		return []OutputEvent{
+			{SessionID: session.ID, Message: reason},
+			{SessionID: session.ID, Message: "Who are you?"},
+		}
+	}
+	session.State = StateChecking
+	if event.checked != "" {
+		// Replayed from a recording, which says how the check went
+		return g.finishLoginPassword(session, event.checked == passwordRight)
+	}
+	hash, owner, password := session.Account.Password, g.isOwner(session.Account), event.Input
+	g.offload(session, func() job {
+		right := g.checkAccountPassword(hash, owner, password)
+		return job{exclusive: always, run: func() []OutputEvent {
+			return g.finishLoginPassword(session, right)
+		}}
+	})
+	return nil
+}
+
+// finishLoginPassword logs the session in if the password it gave was
+// right, and sends it back to choosing a name if not.
+func (g *Game) finishLoginPassword(session *Session, right bool) []OutputEvent {
+	if session.State != StateChecking {
+		return nil
+	}
+	g.recordPassword(session, right)
+	name := session.Name
+	if !right {
+		g.failLogin(session)
+		session.Name, session.Account, session.State = "", nil, StateNaming
+		g.audit(session, "login-refused", name, "wrong password")
+		return []OutputEvent{
+			{SessionID: session.ID, Message: "Wrong password."},
+			{SessionID: session.ID, Message: "Who are you?"},
+		}
+	}
+	delete(g.loginFailures, "account "+strings.ToLower(name))
+	if _, exists := g.usernames[strings.ToLower(name)]; exists {
+		// Someone else logged in while the password was being typed
+		session.Name, session.Account, session.State = "", nil, StateNaming
+		return []OutputEvent{{
+			SessionID: session.ID,
+			Message:   fmt.Sprintf("Username '%s' is already taken. Please enter a different username.", name),
+		}}
+	}
+	return g.login(session)
+}
+
+// login puts a session that has named its account, and proved it is
+// theirs if need be, into the game.
+func (g *Game) login(session *Session) []OutputEvent {
+	account := session.Account
+	session.State = StatePlaying
+	if g.isOwner(account) {
+		account.Role = RoleOwner
+	}
+	g.applyRole(session)
+	account.LastLogin = g.clock.Now()
+	account.LastIP = sessionIP(session)
+	g.audit(session, "login", "", string(account.role()))
+	g.usernames[strings.ToLower(session.Name)] = session
+	g.rejoinChannels(session)
+
+	messages := []OutputEvent{{
+		SessionID: session.ID,
+		Message:   fmt.Sprintf("Welcome, %s!", session.Name),
+	}}
+	messages = append(messages, g.deliverOfflineTells(session)...)
+	messages = append(messages, announceMail(session)...)
+	return append(messages, g.collectBroadcastMessages(session.Room, fmt.Sprintf("%s has joined the room.", session.Name), "")...)
+}
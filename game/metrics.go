@@ -0,0 +1,102 @@
+package game
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"mud/metrics"
+)
+
+// defaultStallTimeout is how long the game may take over one input event
+// or job before it is considered stuck.
+const defaultStallTimeout = 10 * time.Second
+
+// gameMetrics are the metrics the game keeps about itself.
+type gameMetrics struct {
+	registry        *metrics.Registry
+	sessions        *metrics.Gauge
+	inputEvents     *metrics.Counter
+	droppedInput    *metrics.Counter
+	droppedOutput   *metrics.Counter
+	slowDisconnects *metrics.Counter
+	queuedOutput    *metrics.Gauge
+	commandDuration *metrics.Histogram
+}
+
+func (g *Game) newMetrics() *gameMetrics {
+	registry := metrics.NewRegistry()
+	m := &gameMetrics{
+		registry:        registry,
+		sessions:        registry.NewGauge("mud_sessions", "Connected sessions.", "transport"),
+		inputEvents:     registry.NewCounter("mud_input_events_total", "Input events handled by the game."),
+		droppedInput:    registry.NewCounter("mud_input_dropped_total", "Lines of input ignored because a session sent them too fast."),
+		droppedOutput:   registry.NewCounter("mud_output_dropped_total", "Messages dropped because a session could not keep up."),
+		slowDisconnects: registry.NewCounter("mud_slow_disconnects_total", "Sessions disconnected because they could not keep up with their output."),
+		queuedOutput:    registry.NewGauge("mud_output_queued_bytes", "Bytes of output waiting to be sent."),
+		commandDuration: registry.NewHistogram("mud_command_duration_seconds", "Time taken to run commands.", metrics.DefaultBuckets, "command"),
+	}
+	registry.NewGaugeFunc("mud_input_queue_depth", "Input events waiting to be handled.", func() float64 {
+		return float64(len(g.inputChannel))
+	})
+	registry.NewGaugeFunc("mud_room_jobs_waiting", "Jobs waiting for the actors of rooms.", func() float64 {
+		waiting := 0
+		for _, room := range g.rooms {
+			waiting += room.actor.waiting()
+		}
+		return float64(waiting)
+	})
+	return m
+}
+
+// Metrics returns the registry holding the game's metrics.
+func (g *Game) Metrics() *metrics.Registry {
+	return g.metrics.registry
+}
+
+// ConnectionOpened counts a new connection over a transport such as
+// "telnet". ConnectionClosed must be called when it closes.
+func (g *Game) ConnectionOpened(transport string) {
+	g.metrics.sessions.Inc(transport)
+}
+
+// ConnectionClosed counts a connection opened with ConnectionOpened as
+// closed.
+func (g *Game) ConnectionClosed(transport string) {
+	g.metrics.sessions.Dec(transport)
+}
+
+// Healthy returns an error if the game has stopped handling input, or if
+// the actor of a room or channel is stuck on a job.
+func (g *Game) Healthy() error {
+	g.actorsMu.Lock()
+	defer g.actorsMu.Unlock()
+	for a := range g.actors {
+		if started := a.busySince.Load(); started != 0 {
+			if stuck := time.Since(time.Unix(0, started)); stuck > g.stallTimeout {
+				return fmt.Errorf("%s stuck on a job for %s", a.name, stuck.Round(time.Second))
+			}
+		}
+	}
+	if waiting := len(g.inputChannel); waiting > 0 {
+		if idle := time.Since(time.Unix(0, g.lastHandled.Load())); idle > g.stallTimeout {
+			return fmt.Errorf("%d input events waiting, none handled for %s", waiting, idle.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// StatusHandler serves the game's metrics on /metrics and its health check
+// on /healthz.
+func (g *Game) StatusHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", g.Metrics().Handler())
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		if err := g.Healthy(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	return mux
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"math/rand"
+	"time"
+)
+
+// Option configures a Game created by NewGame.
+type Option func(*Game)
+
+// WithBareCommands makes input that does not start with "/" be parsed as a
+// command, the way traditional MUDs do. Players then chat with "say" or "'".
+func WithBareCommands(enabled bool) Option {
+	return func(g *Game) {
+		g.bareCommands = enabled
+	}
+}
+
+// WithDataDir makes the game keep player accounts, mail and boards in dir.
+// Without it, they are lost when the server stops.
+func WithDataDir(dir string) Option {
+	return func(g *Game) {
+		g.dataDir = dir
+	}
+}
+
+// WithSocials adds socials to the built-in ones, replacing any with the
+// same name.
+func WithSocials(socials []Social) Option {
+	return func(g *Game) {
+		for _, social := range socials {
+			g.socials[social.Name] = social
+		}
+	}
+}
+
+// WithOwner gives the player with the given name the owner role, so a new
+// server has someone who can hand out the other roles. The owner logs in
+// with password until they set one of their own with /password. Without a
+// password nobody can log in as the owner.
+func WithOwner(name, password string) Option {
+	return func(g *Game) {
+		g.owner, g.ownerPassword = name, password
+	}
+}
+
+// WithRolePermissions grants permissions to role and every role above it,
+// so commands registered with permissions of their own can be used.
+func WithRolePermissions(role Role, permissions ...Permission) Option {
+	return func(g *Game) {
+		g.grants[role] = append(g.grants[role], permissions...)
+	}
+}
+
+// WithPrivateMessageLogging makes the server log the text of whispers.
+// Without it, only who whispered to whom is logged.
+func WithPrivateMessageLogging(enabled bool) Option {
+	return func(g *Game) {
+		g.logPrivate = enabled
+	}
+}
+
+// WithClock makes the game tell the time by clock instead of the clock on
+// the wall, so simulations can control time.
+func WithClock(clock Clock) Option {
+	return func(g *Game) {
+		g.clock = clock
+	}
+}
+
+// WithRand makes the game draw its random numbers from source instead of
+// a source seeded from the time, so simulations can repeat them.
+func WithRand(source rand.Source) Option {
+	return func(g *Game) {
+		g.random = newRandom(source)
+	}
+}
+
+// WithInputRateLimit lets each session send burst lines at once and rate
+// lines a second after that. A rate of 0 lifts the limit.
+func WithInputRateLimit(rate float64, burst int) Option {
+	return func(g *Game) {
+		g.inputRate, g.inputBurst = rate, burst
+	}
+}
+
+// WithRecording makes the game write every session's input and output to
+// w, one JSON Record per line, so sessions can be replayed with Replay.
+// Recordings include private messages, but not passwords.
+func WithRecording(w io.Writer) Option {
+	return func(g *Game) {
+		g.recorder = &recorder{encoder: json.NewEncoder(w)}
+	}
+}
+
+// WithStallTimeout sets how long a room or channel may spend on one job, or
+// the game may leave input waiting, before Healthy reports it stuck.
+func WithStallTimeout(d time.Duration) Option {
+	return func(g *Game) {
+		g.stallTimeout = d
+	}
+}
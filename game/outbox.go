@@ -0,0 +1,182 @@
+package game
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"mud/metrics"
+)
+
+const (
+	// A session with more than outboxSoftLimit bytes waiting for longer
+	// than slowConsumerGrace, or more than outboxHardLimit bytes at all, is
+	// too slow to keep.
+	outboxSoftLimit   = 64 << 10
+	outboxHardLimit   = 1 << 20
+	slowConsumerGrace = 10 * time.Second
+
+	// deliveryTimeout is how long the transport may take to accept output
+	// before the session is given up on.
+	deliveryTimeout = 30 * time.Second
+)
+
+var errSlowConsumer = errors.New("session is not keeping up with its output")
+
+// outbox queues output for a session and hands it to the transport as fast
+// as the transport takes it. Waiting messages are coalesced into one event.
+type outbox struct {
+	out         chan OutputEvent // read by the transport, closed once the outbox is done
+	wake        chan struct{}
+	queuedGauge *metrics.Gauge
+
+	mu        sync.Mutex
+	queue     []OutputEvent
+	size      int       // bytes queued or being handed over
+	overSince time.Time // when size went over the soft limit, zero while under it
+	closing   bool      // nothing more is accepted; out closes once the queue drains
+	handing   bool      // an event has been taken from the queue but not yet by the transport
+	closed    bool      // out has been closed
+}
+
+func newOutbox(queuedGauge *metrics.Gauge) *outbox {
+	o := &outbox{
+		out:         make(chan OutputEvent),
+		wake:        make(chan struct{}, 1),
+		queuedGauge: queuedGauge,
+	}
+	go o.pump()
+	return o
+}
+
+// push queues an event. Unless the event is a Quit, which is always
+// queued, it returns errSlowConsumer if the session has fallen too far
+// behind. Nothing is queued after a Quit.
+func (o *outbox) push(event OutputEvent) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.closing {
+		return nil
+	}
+	if !event.Quit && (o.size > outboxHardLimit || (!o.overSince.IsZero() && time.Since(o.overSince) > slowConsumerGrace)) {
+		return errSlowConsumer
+	}
+	o.queue = append(o.queue, event)
+	o.grow(len(event.Message))
+	if event.Quit {
+		o.closing = true
+	}
+	o.signal()
+	return nil
+}
+
+// abandon throws away the queued output and queues a final Quit with the
+// reason instead. It returns how many events were thrown away.
+func (o *outbox) abandon(reason string) int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	dropped := len(o.queue)
+	for _, event := range o.queue {
+		o.grow(-len(event.Message))
+	}
+	o.queue = []OutputEvent{{Message: reason, Quit: true}}
+	o.grow(len(reason))
+	o.closing = true
+	o.signal()
+	return dropped
+}
+
+// empty reports whether the transport has taken everything queued, and
+// been told there is no more if the outbox is closing.
+func (o *outbox) empty() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return len(o.queue) == 0 && !o.handing && (!o.closing || o.closed)
+}
+
+// close delivers what is queued and then closes the transport's channel.
+func (o *outbox) close() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.closing = true
+	o.signal()
+}
+
+func (o *outbox) grow(bytes int) {
+	o.size += bytes
+	o.queuedGauge.Add(float64(bytes))
+	switch {
+	case o.size <= outboxSoftLimit:
+		o.overSince = time.Time{}
+	case o.overSince.IsZero():
+		o.overSince = time.Now()
+	}
+}
+
+func (o *outbox) signal() {
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// pump hands queued output to the transport until the outbox is closed and
+// empty, or the transport stops taking it.
+func (o *outbox) pump() {
+	defer func() {
+		close(o.out)
+		o.mu.Lock()
+		o.closed = true
+		o.mu.Unlock()
+	}()
+	for {
+		o.mu.Lock()
+		for len(o.queue) == 0 {
+			if o.closing {
+				o.mu.Unlock()
+				return
+			}
+			o.mu.Unlock()
+			<-o.wake
+			o.mu.Lock()
+		}
+		bytes := 0
+		for _, event := range o.queue {
+			bytes += len(event.Message)
+		}
+		event := coalesce(o.queue)
+		o.queue, o.handing = nil, true
+		o.mu.Unlock()
+
+		select {
+		case o.out <- event:
+		case <-time.After(deliveryTimeout):
+			o.mu.Lock()
+			o.grow(-o.size)
+			o.queue, o.closing, o.handing = nil, true, false
+			o.mu.Unlock()
+			return
+		}
+
+		o.mu.Lock()
+		o.grow(-bytes)
+		o.handing = false
+		o.mu.Unlock()
+	}
+}
+
+// coalesce joins events into one, so a transport writes them all at once.
+func coalesce(events []OutputEvent) OutputEvent {
+	if len(events) == 1 {
+		return events[0]
+	}
+	lines := make([]string, len(events))
+	combined := OutputEvent{SessionID: events[0].SessionID}
+	for i, event := range events {
+		lines[i] = event.Message
+		combined.Quit = combined.Quit || event.Quit
+	}
+	combined.Message = strings.Join(lines, "\n")
+	return combined
+}
@@ -0,0 +1,32 @@
+package game
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// random is the game's source of random numbers. Everything random players
+// can see draws from it, so simulations can seed it and get the same
+// numbers on every run. It is safe for use by several actors at once.
+type random struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+func newRandom(source rand.Source) *random {
+	return &random{rng: rand.New(source)}
+}
+
+// RandIntn returns a random number from 0 up to but not including n, from
+// the game's random source. It panics if n is not positive.
+func (g *Game) RandIntn(n int) int {
+	g.random.mu.Lock()
+	defer g.random.mu.Unlock()
+	return g.random.rng.Intn(n)
+}
+
+// defaultRandomSource is the random source of games not made WithRand.
+func defaultRandomSource() rand.Source {
+	return rand.NewSource(time.Now().UnixNano())
+}
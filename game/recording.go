@@ -0,0 +1,228 @@
+package game
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Kinds of records in a recording.
+const (
+	RecordConnect    = "connect"
+	RecordInput      = "input"
+	RecordDisconnect = "disconnect"
+	RecordOutput     = "output"
+	RecordPassword   = "password"
+)
+
+// Texts that stand in for passwords in a recording. A password record says
+// whether the password was right instead of what it was.
+const (
+	redacted      = "***"
+	passwordRight = "right"
+	passwordWrong = "wrong"
+)
+
+// A Record is one event in a recording: a session connecting, sending a
+// line, giving a password, being sent a message or going away.
+type Record struct {
+	Time    time.Time `json:"time"`
+	Session string    `json:"session"`
+	Kind    string    `json:"kind"`
+	Text    string    `json:"text,omitempty"`
+}
+
+// recorder writes every session's input and output as JSON lines.
+type recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// record adds an event to the recording, if there is one.
+func (g *Game) record(sessionID, kind, text string) {
+	r := g.recorder
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := r.encoder.Encode(Record{Time: g.clock.Now(), Session: sessionID, Kind: kind, Text: text}); err != nil {
+		storageLog.Error("Failed to write the recording", "err", err)
+	}
+}
+
+// recordInput adds a connection or disconnection to the recording.
+func (g *Game) recordInput(event InputEvent) {
+	switch event.Kind {
+	case InputConnect:
+		g.record(event.SessionID, RecordConnect, "")
+	case InputDisconnect:
+		g.record(event.SessionID, RecordDisconnect, "")
+	}
+}
+
+// recordLine adds a line of input from a session to the recording, with
+// the arguments of /password left out. It is called with the world lock
+// held.
+func (g *Game) recordLine(session *Session, event InputEvent) {
+	if g.recorder == nil {
+		return
+	}
+	input := event.Input
+	if g.setsPassword(session, input) {
+		input = "/password " + redacted
+	}
+	g.record(event.SessionID, RecordInput, input)
+}
+
+// recordRejected adds a line the game turned down to the recording. A line
+// from a session that has not logged in may be a password, so its text is
+// left out.
+func (g *Game) recordRejected(event InputEvent) {
+	if event.recordLater {
+		g.record(event.SessionID, RecordInput, redacted)
+	}
+}
+
+// recordPassword adds to the recording whether the password a session gave
+// was right.
+func (g *Game) recordPassword(session *Session, right bool) {
+	if right {
+		g.record(session.ID, RecordPassword, passwordRight)
+	} else {
+		g.record(session.ID, RecordPassword, passwordWrong)
+	}
+}
+
+// setsPassword reports whether input from the session runs /password.
+func (g *Game) setsPassword(session *Session, input string) bool {
+	input = strings.TrimSpace(input)
+	if !g.isCommand(session, input) {
+		return false
+	}
+	lines, _ := g.expandAliases(session, strings.TrimPrefix(input, "/"), 0)
+	for _, line := range lines {
+		name, _ := g.splitCommandLine(line)
+		if command, _ := g.findCommand(session, name); command != nil && command.Name == passwordCommand.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// ReadRecording reads a recording written by a game made WithRecording.
+func ReadRecording(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		var record Record
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// Replay plays the input of a recording into a new game made with options,
+// on a clock that follows the times in the recording, and compares what
+// each session is sent with what was recorded. It returns a report of the
+// differences, which is empty if the output matched.
+//
+// The new game starts out empty, so a recording only replays exactly if it
+// was made from the start of a server without saved data. Passwords are
+// not recorded: logins replay as the recorded checks went, but /password
+// does not replay.
+func Replay(records []Record, options ...Option) (string, error) {
+	if len(records) == 0 {
+		return "", nil
+	}
+	sim := newSimulation(records[0].Time, options...)
+	defer sim.Game.Shutdown()
+
+	// A session ID can come back once its connection has gone, so each
+	// connection is known by its ID and how many times the ID was seen
+	var connections []*simulatedPlayer
+	current := make(map[string]*simulatedPlayer)
+	recorded := make(map[*simulatedPlayer][]string)
+	for _, record := range records {
+		p, exists := current[record.Session]
+		if record.Kind != RecordConnect && !exists {
+			return "", fmt.Errorf("%s has a record of kind %s before connecting", record.Session, record.Kind)
+		}
+		if record.Kind == RecordOutput {
+			recorded[p] = append(recorded[p], strings.Split(record.Text, "\n")...)
+			continue
+		}
+		if wait := record.Time.Sub(sim.Clock.Now()); wait > 0 {
+			sim.Clock.Advance(wait)
+		}
+		var err error
+		switch record.Kind {
+		case RecordConnect:
+			name := record.Session
+			if seen := countConnections(connections, record.Session); seen > 0 {
+				name = fmt.Sprintf("%s (%d)", record.Session, seen+1)
+			}
+			if p, err = sim.connect(name, record.Session); err == nil {
+				connections = append(connections, p)
+				current[record.Session] = p
+			}
+		case RecordInput:
+			if event := (InputEvent{SessionID: record.Session, Input: record.Text}); sim.Game.admitInput(&event) {
+				sim.Game.routeInput(event)
+			}
+		case RecordPassword:
+			if event := (InputEvent{SessionID: record.Session, Input: redacted, checked: record.Text}); sim.Game.admitInput(&event) {
+				sim.Game.routeInput(event)
+			}
+		case RecordDisconnect:
+			sim.Game.routeInput(InputEvent{SessionID: record.Session, Kind: InputDisconnect})
+		default:
+			return "", fmt.Errorf("unknown kind of record %q", record.Kind)
+		}
+		if err == nil {
+			err = sim.settle()
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Once a session's output goes its own way the rest rarely lines up, so
+	// only where it first differs is reported
+	var report strings.Builder
+	for _, p := range connections {
+		want, got := recorded[p], p.received
+		for i := 0; i < len(want) || i < len(got); i++ {
+			if i < len(want) && i < len(got) && want[i] == got[i] {
+				continue
+			}
+			fmt.Fprintf(&report, "%s: line %d differs (%d lines recorded, %d on replay)\n", p.name, i+1, len(want), len(got))
+			if i < len(want) {
+				fmt.Fprintf(&report, "- %s\n", want[i])
+			}
+			if i < len(got) {
+				fmt.Fprintf(&report, "+ %s\n", got[i])
+			}
+			break
+		}
+	}
+	return report.String(), nil
+}
+
+// countConnections returns how many of the connections had the session ID.
+func countConnections(connections []*simulatedPlayer, id string) int {
+	count := 0
+	for _, p := range connections {
+		if p.session.ID == id {
+			count++
+		}
+	}
+	return count
+}
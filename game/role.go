@@ -0,0 +1,89 @@
+package game
+
+import (
+	"strings"
+)
+
+// Role is a player's standing on the server. Every role has the
+// permissions of the roles below it.
+type Role string
+
+const (
+	RolePlayer    Role = "player"
+	RoleBuilder   Role = "builder"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+	RoleOwner     Role = "owner"
+)
+
+// roles lists every role from least to most trusted.
+var roles = []Role{RolePlayer, RoleBuilder, RoleModerator, RoleAdmin, RoleOwner}
+
+const (
+	PermissionKick      Permission = "kick"
+	PermissionMute      Permission = "mute"
+	PermissionBan       Permission = "ban"
+	PermissionGoto      Permission = "goto"
+	PermissionTransfer  Permission = "transfer"
+	PermissionForce     Permission = "force"
+	PermissionBroadcast Permission = "broadcast"
+	PermissionSetRole   Permission = "set-role"
+	PermissionShutdown  Permission = "shutdown"
+)
+
+// defaultRolePermissions holds the permissions each role adds to those of
+// the roles below it. Games can add more with WithRolePermissions.
+var defaultRolePermissions = map[Role][]Permission{
+	RoleBuilder:   {PermissionBuild, PermissionGoto},
+	RoleModerator: {PermissionModerateChannels, PermissionModerateBoards, PermissionOverrideIgnore, PermissionKick, PermissionMute, PermissionTransfer},
+	RoleAdmin:     {PermissionBan, PermissionForce, PermissionBroadcast, PermissionSetRole, PermissionAudit, PermissionLogLevel},
+	RoleOwner:     {PermissionShutdown},
+}
+
+// parseRole returns the role with the given name.
+func parseRole(name string) (Role, bool) {
+	for _, role := range roles {
+		if strings.EqualFold(string(role), name) {
+			return role, true
+		}
+	}
+	return "", false
+}
+
+// rank orders roles; a higher rank is more trusted.
+func (r Role) rank() int {
+	for i, role := range roles {
+		if role == r {
+			return i
+		}
+	}
+	return 0
+}
+
+// permissionsOf returns every permission the role grants.
+func (g *Game) permissionsOf(r Role) map[Permission]bool {
+	granted := make(map[Permission]bool)
+	for _, role := range roles[:r.rank()+1] {
+		for _, p := range g.grants[role] {
+			granted[p] = true
+		}
+	}
+	return granted
+}
+
+func (a *Account) role() Role {
+	if role, ok := parseRole(string(a.Role)); ok {
+		return role
+	}
+	return RolePlayer
+}
+
+// outranks reports whether the account may use staff commands on other.
+func (a *Account) outranks(other *Account) bool {
+	return a.role().rank() > other.role().rank()
+}
+
+// applyRole gives the session the permissions of its account's role.
+func (g *Game) applyRole(s *Session) {
+	s.Permissions = g.permissionsOf(s.Account.role())
+}
@@ -0,0 +1,233 @@
+package game
+
+import (
+	"bufio"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// SimulationStart is the time a simulation's clock starts at.
+var SimulationStart = time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+// SimulationSeed seeds the random source of simulations not given one of
+// their own with WithRand.
+const SimulationSeed = 1
+
+// settleTimeout is how long a simulation waits for the game to finish
+// reacting to a step before it gives up.
+const settleTimeout = 10 * time.Second
+
+// Simulation drives a game one step at a time on a virtual clock. After
+// each step it waits until the game has done everything the step caused,
+// so what every player is sent, and the transcript it makes, is the same
+// on every run.
+type Simulation struct {
+	Game  *Game
+	Clock *VirtualClock
+
+	players    []*simulatedPlayer
+	byName     map[string]*simulatedPlayer
+	transcript strings.Builder
+}
+
+// simulatedPlayer is a connection to a simulated game, known by the name
+// the script gives it.
+type simulatedPlayer struct {
+	name     string
+	session  *Session
+	output   <-chan OutputEvent
+	lines    []string // received since the last step was written down
+	received []string // every line received
+	gone     bool     // the game has closed the player's output
+}
+
+// NewSimulation starts a game with the given options on a virtual clock
+// stopped at SimulationStart, drawing random numbers from a source seeded
+// with SimulationSeed.
+func NewSimulation(options ...Option) *Simulation {
+	return newSimulation(SimulationStart, options...)
+}
+
+func newSimulation(start time.Time, options ...Option) *Simulation {
+	clock := NewVirtualClock(start)
+	return &Simulation{
+		Game:   NewGame(append([]Option{WithClock(clock), WithRand(rand.NewSource(SimulationSeed))}, options...)...),
+		Clock:  clock,
+		byName: make(map[string]*simulatedPlayer),
+	}
+}
+
+// Run plays a script with one step per line:
+//
+//	Alice> Alice     Alice sends a line, connecting first if she is new
+//	Alice!           Alice's connection drops
+//	+5m              the clock moves on by a duration
+//
+// Blank lines and lines starting with # are skipped. Each step and what
+// it sent to whom is added to the transcript.
+func (s *Simulation) Run(script string) error {
+	scanner := bufio.NewScanner(strings.NewReader(script))
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		var err error
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "+"):
+			var d time.Duration
+			if d, err = time.ParseDuration(line[1:]); err == nil {
+				err = s.Advance(d)
+			}
+		case strings.HasSuffix(line, "!") && !strings.ContainsAny(line, "> "):
+			err = s.Disconnect(strings.TrimSuffix(line, "!"))
+		default:
+			name, input, found := strings.Cut(line, ">")
+			if !found || name == "" {
+				err = fmt.Errorf("expected Name> input, Name! or +duration")
+			} else {
+				err = s.Send(name, strings.TrimPrefix(input, " "))
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("line %d: %q: %w", number, line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Send sends a line of input from the named player, connecting them first
+// if they have not been seen before.
+func (s *Simulation) Send(name, input string) error {
+	s.step(fmt.Sprintf("%s> %s", name, input))
+	p, exists := s.byName[name]
+	if !exists {
+		var err error
+		if p, err = s.connect(name, fmt.Sprintf("127.0.0.1:%d", 10000+len(s.players))); err != nil {
+			return err
+		}
+	}
+	if p.gone {
+		return fmt.Errorf("%s has been disconnected", name)
+	}
+	if event := (InputEvent{SessionID: p.session.ID, Input: input}); s.Game.admitInput(&event) {
+		s.Game.routeInput(event)
+	}
+	return s.settle()
+}
+
+// Disconnect drops the named player's connection.
+func (s *Simulation) Disconnect(name string) error {
+	s.step(name + "!")
+	p, exists := s.byName[name]
+	if !exists {
+		return fmt.Errorf("%s has not connected", name)
+	}
+	s.Game.routeInput(InputEvent{SessionID: p.session.ID, Kind: InputDisconnect})
+	return s.settle()
+}
+
+// Advance moves the clock on by d.
+func (s *Simulation) Advance(d time.Duration) error {
+	s.step("+" + d.String())
+	s.Clock.Advance(d)
+	return s.settle()
+}
+
+// Connected reports whether the named player has connected and the game
+// has not yet ended their session.
+func (s *Simulation) Connected(name string) bool {
+	p, exists := s.byName[name]
+	return exists && !p.gone
+}
+
+// Transcript returns every step so far, each followed by the lines it sent
+// to each player.
+func (s *Simulation) Transcript() string {
+	s.record()
+	return s.transcript.String()
+}
+
+// connect opens a session with the given ID for the player called name.
+func (s *Simulation) connect(name, id string) (*simulatedPlayer, error) {
+	created := make(chan bool, 1)
+	s.Game.routeInput(InputEvent{SessionID: id, Kind: InputConnect, ResponseChan: created})
+	if !<-created {
+		return nil, fmt.Errorf("failed to create a session for %s", name)
+	}
+	s.Game.mu.RLock()
+	session := s.Game.sessions[id]
+	s.Game.mu.RUnlock()
+	p := &simulatedPlayer{name: name, session: session, output: session.OutputChannel}
+	s.players = append(s.players, p)
+	s.byName[name] = p
+	return p, nil
+}
+
+// step writes down what the previous step sent and starts a new one.
+func (s *Simulation) step(description string) {
+	s.record()
+	fmt.Fprintln(&s.transcript, description)
+}
+
+// record writes down the lines players have received, player by player in
+// the order they connected.
+func (s *Simulation) record() {
+	for _, p := range s.players {
+		for _, line := range p.lines {
+			fmt.Fprintln(&s.transcript, strings.TrimRight("  "+p.name+": "+line, " "))
+		}
+		p.lines = nil
+	}
+}
+
+// settle waits until the game has nothing left to do: timers that are due
+// have run, every actor is idle and every player has been handed all of
+// their output.
+func (s *Simulation) settle() error {
+	deadline := time.Now().Add(settleTimeout)
+	for {
+		s.Clock.Advance(0)
+		if !s.receive() && s.Game.jobs.Load() == 0 && s.delivered() {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the game was still busy after %s", settleTimeout)
+		}
+		time.Sleep(10 * time.Microsecond)
+	}
+}
+
+// receive takes whatever output is ready, reporting whether there was any.
+func (s *Simulation) receive() bool {
+	received := false
+	for _, p := range s.players {
+		for more := !p.gone; more; {
+			select {
+			case event, ok := <-p.output:
+				if !ok {
+					p.gone, more = true, false
+					break
+				}
+				lines := strings.Split(event.Message, "\n")
+				p.lines = append(p.lines, lines...)
+				p.received = append(p.received, lines...)
+				received = true
+			default:
+				more = false
+			}
+		}
+	}
+	return received
+}
+
+// delivered reports whether every player's outbox is empty.
+func (s *Simulation) delivered() bool {
+	for _, p := range s.players {
+		if !p.session.outbox.empty() {
+			return false
+		}
+	}
+	return true
+}
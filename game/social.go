@@ -0,0 +1,168 @@
+package game
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+//go:embed socials.json
+var defaultSocials []byte
+
+// Social is a canned action such as smiling or waving. In the messages, $n
+// is replaced by the actor's name and $N by the target's.
+type Social struct {
+	Name       string `json:"name"`
+	Self       string `json:"self"`        // seen by the actor when there is no target
+	Room       string `json:"room"`        // seen by everyone else when there is no target
+	TargetSelf string `json:"target_self"` // seen by the actor when there is a target
+	Target     string `json:"target"`      // seen by the target
+	TargetRoom string `json:"target_room"` // seen by everyone else when there is a target
+}
+
+// LoadSocials reads socials from a JSON file holding a list of Social.
+func LoadSocials(path string) ([]Social, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading socials: %w", err)
+	}
+	return parseSocials(data)
+}
+
+func parseSocials(data []byte) ([]Social, error) {
+	var socials []Social
+	if err := json.Unmarshal(data, &socials); err != nil {
+		return nil, fmt.Errorf("parsing socials: %w", err)
+	}
+	for _, social := range socials {
+		if social.Name == "" || social.Self == "" {
+			return nil, fmt.Errorf("parsing socials: every social needs a name and a self message")
+		}
+		targeted := social.Target != "" || social.TargetSelf != "" || social.TargetRoom != ""
+		if targeted && (social.Target == "" || social.TargetSelf == "" || social.TargetRoom == "") {
+			return nil, fmt.Errorf("parsing socials: %s needs all of target, target_self and target_room, or none", social.Name)
+		}
+	}
+	return socials, nil
+}
+
+// registerSocials adds a command for every social. Socials whose names are
+// taken by other commands are skipped.
+func (g *Game) registerSocials() {
+	names := make([]string, 0, len(g.socials))
+	for name := range g.socials {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		social := g.socials[name]
+		cmd := Command{
+			Name:     social.Name,
+			Short:    fmt.Sprintf("Perform the %s social.", social.Name),
+			Category: "Socials",
+			Local:    true,
+			Handler:  socialHandler(social),
+		}
+		if social.Target != "" {
+			cmd.Args = []ArgSpec{{Name: "target", Kind: ArgTarget, Optional: social.Room != ""}}
+		}
+		if err := g.RegisterCommand(cmd); err != nil {
+			gameLog.Warn("Skipping social", "social", social.Name, "err", err)
+		}
+	}
+}
+
+func socialHandler(social Social) CommandHandler {
+	return func(g *Game, session *Session, args *Args) []OutputEvent {
+		if refusal := g.chatRefusal(session, "/"+social.Name); refusal != nil {
+			return refusal
+		}
+		if !args.Has("target") {
+			if social.Room == "" {
+				return []OutputEvent{{SessionID: session.ID, Message: social.Self}}
+			}
+			return append(
+				[]OutputEvent{{SessionID: session.ID, Message: expandSocial(social.Self, session, nil)}},
+				from(session, g.collectBroadcastMessages(session.Room, expandSocial(social.Room, session, nil), session.ID))...,
+			)
+		}
+
+		if target := args.Target("target"); target.All {
+			// Everyone matched but the player themselves
+			var messages []OutputEvent
+			for _, other := range g.findAllInRoom(session.Room, target) {
+				if other != session {
+					messages = append(messages, g.doSocial(social, session, other)...)
+				}
+			}
+			if messages == nil {
+				return []OutputEvent{{SessionID: session.ID, Message: "You don't see them here."}}
+			}
+			return messages
+		}
+		target := g.findInRoom(session.Room, args.Target("target"))
+		if target == nil {
+			return []OutputEvent{{SessionID: session.ID, Message: "You don't see them here."}}
+		}
+		if target == session {
+			return []OutputEvent{{SessionID: session.ID, Message: "You can't do that to yourself."}}
+		}
+		return g.doSocial(social, session, target)
+	}
+}
+
+// doSocial returns the messages of a social the session does to target.
+func (g *Game) doSocial(social Social, session, target *Session) []OutputEvent {
+	return append(
+		[]OutputEvent{
+			{SessionID: session.ID, Message: expandSocial(social.TargetSelf, session, target)},
+			{SessionID: target.ID, Message: expandSocial(social.Target, session, target), From: session.Name},
+		},
+		from(session, g.collectBroadcastMessages(session.Room, expandSocial(social.TargetRoom, session, target), session.ID, target.ID))...,
+	)
+}
+
+func expandSocial(message string, actor, target *Session) string {
+	message = strings.ReplaceAll(message, "$n", actor.Name)
+	if target != nil {
+		message = strings.ReplaceAll(message, "$N", target.Name)
+	}
+	return message
+}
+
+// findInRoom resolves a target to a player in the room. Players are matched
+// by name prefix in name order, so "2.al" is the second player whose name
+// starts with "al". A target naming every match, such as "all.al", finds
+// nobody; see findAllInRoom.
+func (g *Game) findInRoom(room *Room, target Target) *Session {
+	if target.All {
+		return nil
+	}
+	matches := g.findAllInRoom(room, target)
+	for _, s := range matches {
+		if strings.EqualFold(s.Name, target.Keyword) && target.Ordinal == 1 {
+			return s
+		}
+	}
+	if target.Ordinal < 1 || target.Ordinal > len(matches) {
+		return nil
+	}
+	return matches[target.Ordinal-1]
+}
+
+// findAllInRoom returns every player in the room whose name starts with the
+// target's keyword, in name order. "all" on its own matches everyone.
+func (g *Game) findAllInRoom(room *Room, target Target) []*Session {
+	var matches []*Session
+	for _, s := range room.Sessions {
+		if s.loggedIn() && strings.HasPrefix(strings.ToLower(s.Name), target.Keyword) {
+			matches = append(matches, s)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].Name < matches[j].Name })
+	return matches
+}
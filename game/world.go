@@ -0,0 +1,149 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// directions maps each direction to its opposite.
+var directions = map[string]string{
+	"north": "south",
+	"south": "north",
+	"east":  "west",
+	"west":  "east",
+	"up":    "down",
+	"down":  "up",
+}
+
+// roomSpec describes a room of the built-in world. Exits map directions to
+// room names.
+type roomSpec struct {
+	Name        string
+	Description string
+	Exits       map[string]string
+}
+
+var defaultWorld = []roomSpec{
+	{"Lobby", "A quiet hall where new arrivals gather. A doorway leads north to the town square.", map[string]string{"north": "Town Square"}},
+	{"Town Square", "The bustling heart of town, paved in worn cobblestones around a dry fountain.", map[string]string{"south": "Lobby", "east": "Market", "west": "Tavern", "north": "City Gate"}},
+	{"Market", "Stalls crowd the street, their keepers calling out prices.", map[string]string{"west": "Town Square"}},
+	{"Tavern", "A warm, smoky room full of laughter. A narrow stair leads up.", map[string]string{"east": "Town Square", "up": "Tavern Loft"}},
+	{"Tavern Loft", "A cramped loft above the tavern, quiet enough for private talk.", map[string]string{"down": "Tavern"}},
+	{"City Gate", "Tall wooden gates stand open to the road beyond the town walls.", map[string]string{"south": "Town Square"}},
+}
+
+// buildWorld creates the rooms in specs and links their exits. The first
+// room is where new players start.
+func buildWorld(specs []roomSpec) (map[string]*Room, *Room, error) {
+	rooms := make(map[string]*Room)
+	for _, spec := range specs {
+		rooms[strings.ToLower(spec.Name)] = &Room{
+			Name:        spec.Name,
+			Description: spec.Description,
+			Sessions:    make(map[string]*Session),
+			Exits:       make(map[string]*Room),
+		}
+	}
+	for _, spec := range specs {
+		room := rooms[strings.ToLower(spec.Name)]
+		for direction, target := range spec.Exits {
+			if _, valid := directions[direction]; !valid {
+				return nil, nil, fmt.Errorf("room %s has an exit in unknown direction %s", spec.Name, direction)
+			}
+			destination, exists := rooms[strings.ToLower(target)]
+			if !exists {
+				return nil, nil, fmt.Errorf("room %s has an exit to unknown room %s", spec.Name, target)
+			}
+			room.Exits[direction] = destination
+		}
+	}
+	return rooms, rooms[strings.ToLower(specs[0].Name)], nil
+}
+
+// exitNames returns the room's exit directions in a stable order.
+func (r *Room) exitNames() []string {
+	names := make([]string, 0, len(r.Exits))
+	for direction := range r.Exits {
+		names = append(names, direction)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// describe renders the room as seen by the session.
+func (r *Room) describe(session *Session) string {
+	lines := []string{r.Name, r.Description}
+	if exits := r.exitNames(); len(exits) > 0 {
+		lines = append(lines, "Exits: "+strings.Join(exits, ", "))
+	} else {
+		lines = append(lines, "Exits: none")
+	}
+	if r.Board != nil {
+		lines = append(lines, fmt.Sprintf("The %s is here.", r.Board.Name))
+	}
+	var others []string
+	for _, s := range r.Sessions {
+		if s != session && s.loggedIn() {
+			others = append(others, s.Name)
+		}
+	}
+	if len(others) > 0 {
+		sort.Strings(others)
+		lines = append(lines, "Also here: "+strings.Join(others, ", "))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// moveSession moves the session through an exit of its room, taking along
+// everyone in the room who follows it. The session arrives in the other
+// room once that room's actor gets to it.
+func (g *Game) moveSession(session *Session, direction string) []OutputEvent {
+	from := session.Room
+	to := from.Exits[direction]
+	var followers []*Session
+	for _, s := range from.Sessions {
+		if s.following == session && s.State == StatePlaying {
+			followers = append(followers, s)
+		}
+	}
+	sort.Slice(followers, func(i, j int) bool { return followers[i].Name < followers[j].Name })
+
+	delete(from.Sessions, session.ID)
+	messages := g.collectBroadcastMessages(from, fmt.Sprintf("%s leaves %s.", session.Name, direction))
+	g.enter(session, to, fmt.Sprintf("%s arrives from the %s.", session.Name, directions[direction]))
+
+	for _, follower := range followers {
+		if follower.Room == from {
+			messages = append(messages, OutputEvent{SessionID: follower.ID, Message: fmt.Sprintf("You follow %s %s.", session.Name, direction)})
+			messages = append(messages, g.moveSession(follower, direction)...)
+		}
+	}
+	return messages
+}
+
+// enter sends a session that has left its room on to another one. The
+// other room belongs to its own actor, which adds the session and tells
+// everyone there with announcement. Once enter returns, the session and
+// its input belong to that actor too.
+func (g *Game) enter(session *Session, to *Room, announcement string) {
+	session.Room = to
+	to.actor.post(job{run: func() []OutputEvent {
+		if g.sessions[session.ID] != session || session.Room != to {
+			// The session left the game or was moved on in the meantime
+			return nil
+		}
+		messages := g.collectBroadcastMessages(to, announcement)
+		to.Sessions[session.ID] = session
+		return append(messages, OutputEvent{SessionID: session.ID, Message: to.describe(session)})
+	}})
+	session.home.Store(to)
+}
+
+// place puts a session straight into a room. The world lock must be held
+// exclusively.
+func (g *Game) place(session *Session, room *Room) {
+	session.Room = room
+	room.Sessions[session.ID] = session
+	session.home.Store(room)
+}
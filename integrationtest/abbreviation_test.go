@@ -0,0 +1,62 @@
+package integrationtest
+
+import (
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestCommandAbbreviations(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"/whi Alice hello", []string{"Alice whispers: hello", "You whispered to Alice: hello"}},
+		{"/tell Alice hi", []string{"Alice whispers: hi", "You whispered to Alice: hi"}},
+		{"/wh", []string{"Ambiguous command: wh. Did you mean /whisper or /who?"}},
+		{"/hlep", []string{"Unknown command: hlep. Did you mean /help?"}},
+		{"/", []string{"Type a command after the /, or /help for a list of commands."}},
+		{"/'hello there", []string{"Alice says: hello there"}},
+		{"hello there", []string{"Alice says: hello there"}},
+	}
+	for _, test := range tests {
+		alice.Send(test.input)
+		responses := alice.ExpectLines(len(test.expected))
+		for i, expected := range test.expected {
+			if responses[i] != expected {
+				t.Errorf("Unexpected response %d to %q: got %s, want %s", i+1, test.input, responses[i], expected)
+			}
+		}
+	}
+}
+
+func TestBareCommands(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t, game.WithBareCommands(true))
+
+	alice := s.Login("Alice")
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"tells", "You have not whispered with anyone yet."},
+		{"say hello", "Alice says: hello"},
+		{"'hi", "Alice says: hi"},
+		{"/tells", "You have not whispered with anyone yet."},
+		{"helo", "Unknown command: helo. Did you mean /help?"},
+	}
+	for _, test := range tests {
+		alice.Send(test.input)
+		response := alice.ExpectLines(1)[0]
+		if response != test.expected {
+			t.Errorf("Unexpected response to %q: got %s, want %s", test.input, response, test.expected)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package integrationtest
+
+import (
+	"strings"
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestAdminCommands(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t, game.WithOwner("Alice", "secret"))
+
+	alice := s.LoginWithPassword("Alice", "secret")
+	bob := s.Login("Bob")
+	charlie := s.Login("Charlie")
+	alice.ExpectEventually("Charlie has joined the room.")
+	bob.ExpectEventually("Charlie has joined the room.")
+
+	// Players cannot see staff commands
+	bob.Send("/kick Charlie")
+	bob.ExpectLine("Unknown command: kick")
+
+	// The owner hands out roles below their own
+	alice.Send("/role Bob owner")
+	alice.ExpectLine("You can only hand out roles below your own.")
+	alice.Send("/role Bob moderator")
+	alice.ExpectLine("Bob must set a password with /password before they can be given a staff role.")
+	bob.Send("/password hunter22")
+	bob.ExpectLine("Your password has been set.")
+	alice.Send("/role Bob moderator")
+	alice.ExpectEventually("Bob is now a moderator.")
+	bob.ExpectEventually("Alice made you a moderator.")
+
+	// A mute with a bad duration changes nothing
+	bob.Send("/mute Charlie 5x")
+	bob.ExpectLine("The duration must look like 30m, 12h or 7d.")
+	bob.ExpectLine("Usage: /mute <player> [<duration>]")
+	charlie.Send("/say still talking")
+	bob.ExpectEventually("Charlie says: still talking")
+	alice.ExpectEventually("Charlie says: still talking")
+	charlie.ExpectEventually("Charlie says: still talking")
+
+	// Moderators mute players
+	bob.Send("/mute Charlie 10m")
+	bob.ExpectEventually("Charlie is muted until")
+	charlie.ExpectEventually("You have been muted by Bob.")
+	for _, input := range []string{"/say hello", "/mail send Alice Hello", "/board post Hello"} {
+		charlie.Send(input)
+		if response := charlie.ExpectLines(1)[0]; !strings.HasPrefix(response, "You are muted until") {
+			t.Errorf("Unexpected response to a muted player sending %s: %s", input, response)
+		}
+	}
+	bob.Send("/unmute Charlie")
+	charlie.ExpectEventually("You are no longer muted.")
+
+	// Moderators cannot act on staff of the same rank or above
+	bob.Send("/mute Alice")
+	if response := bob.ExpectEventually("Alice"); response[len(response)-1] != "You cannot do that to Alice." {
+		t.Errorf("Unexpected response to muting the owner: %v", response)
+	}
+
+	// Admins make players run commands
+	alice.Send("/force Charlie /say sorry")
+	charlie.ExpectEventually("Alice forces you to: /say sorry")
+	bob.ExpectEventually("Charlie says: sorry")
+
+	// Staff move around and move players
+	alice.Send("/goto tavern")
+	alice.ExpectEventually("Tavern")
+	bob.ExpectEventually("Alice vanishes.")
+	alice.Send("/transfer Charlie")
+	charlie.ExpectEventually("Alice transfers you to Tavern.")
+	alice.ExpectEventually("Charlie appears out of thin air.")
+
+	alice.Send("/broadcast maintenance soon")
+	bob.ExpectEventually("[Broadcast] Alice: maintenance soon")
+	charlie.ExpectEventually("[Broadcast] Alice: maintenance soon")
+
+	// Kicked players are disconnected
+	bob.Send("/kick Charlie behave")
+	charlie.ExpectEventually("You have been kicked by Bob: behave")
+	charlie.ExpectClosed()
+	alice.ExpectEventually("Charlie has left the room.")
+
+	// Banned players cannot log back in
+	alice.Send("/ban Charlie 1d spamming")
+	alice.ExpectEventually("You banned Charlie and 127.0.0.1.")
+	charlie = s.Connect()
+	charlie.Send("Charlie")
+	charlie.ExpectEventually("You are banned from this server until")
+	charlie.ExpectClosed()
+
+	// Staff are not caught by the address ban
+	bob.Send("/look")
+	bob.ExpectEventually("Lobby")
+
+	alice.Send("/unban Charlie")
+	alice.ExpectEventually("You lifted the ban on Charlie.")
+	alice.Send("/bans")
+	alice.ExpectEventually("Nobody is banned.")
+
+	// Shutting down says goodbye to everyone
+	alice.Send("/shutdown")
+	bob.ExpectEventually("The server is shutting down. Goodbye!")
+	bob.ExpectClosed()
+}
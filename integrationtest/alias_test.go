@@ -0,0 +1,44 @@
+package integrationtest
+
+import (
+	"testing"
+
+	"mud/mudtest"
+)
+
+func TestAliases(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"/alias gw say hi $1;whisper $1 welcome!", []string{"Alias set: gw = say hi $1;whisper $1 welcome!"}},
+		{"/gw Alice", []string{"Alice says: hi Alice", "Alice whispers: welcome!", "You whispered to Alice: welcome!"}},
+		{"/alias s say", []string{"Alias set: s = say"}},
+		{"/s hello there", []string{"Alice says: hello there"}},
+		{"/alias loop loop", []string{"Alias set: loop = loop"}},
+		{"/loop", []string{"Alias loop nests too deeply."}},
+		{"/unalias loop", []string{"Alias removed: loop"}},
+		{"/loop", []string{"Unknown command: loop. Did you mean /look?"}},
+	}
+	for _, test := range tests {
+		alice.Send(test.input)
+		responses := alice.ExpectLines(len(test.expected))
+		for i, expected := range test.expected {
+			if responses[i] != expected {
+				t.Errorf("Unexpected response %d to %q: got %s, want %s", i+1, test.input, responses[i], expected)
+			}
+		}
+	}
+
+	// Aliases survive a restart
+	s = s.Restart()
+	alice = s.Login("alice")
+
+	alice.Send("/s still here")
+	alice.ExpectLine("alice says: still here")
+}
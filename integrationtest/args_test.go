@@ -0,0 +1,65 @@
+package integrationtest
+
+import (
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestCommandArguments(t *testing.T) {
+	t.Parallel()
+	// The cases come faster than players may type
+	s := mudtest.NewServer(t, game.WithInputRateLimit(0, 0))
+
+	alice := s.Login("Alice")
+	s.Login("Albert")
+	s.Login("Alfred")
+	alice.ExpectEventually("Alfred has joined the room.")
+
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"/whisper", []string{"Missing <username>.", "Usage: /whisper <username> <message>"}},
+		{"/whisper Alice", []string{"Missing <message>.", "Usage: /whisper <username> <message>"}},
+		{"/whisper  Alice   hi  there", []string{"Alice whispers: hi  there", "You whispered to Alice: hi  there"}},
+		{`/whisper "Alice" hello there`, []string{"Alice whispers: hello there", "You whispered to Alice: hello there"}},
+		{`/whisper "Alice oops`, []string{"Unterminated quote.", "Usage: /whisper <username> <message>"}},
+
+		// Free text is kept as typed, quotes and all
+		{`/say I'm 6" tall`, []string{`Alice says: I'm 6" tall`}},
+		{`/say "hi"`, []string{`Alice says: "hi"`}},
+		{`/say "hi" there`, []string{`Alice says: "hi" there`}},
+		{`/whisper Alice "quoted"`, []string{`Alice whispers: "quoted"`, `You whispered to Alice: "quoted"`}},
+		{"/tells everyone", []string{"Too many arguments.", "Usage: /tells"}},
+		{"/help help", []string{"List available commands.", "Usage: /help [<command>]", "Aliases: ?"}},
+
+		// Numbers
+		{"/mail read one", []string{"<number> must be a number.", "Usage: /mail [list | read <number> | delete <number> | send <player> <subject>]"}},
+		{"/mail read 99999999999999999999", []string{"<number> must be a number.", "Usage: /mail [list | read <number> | delete <number> | send <player> <subject>]"}},
+		{"/mail read 0", []string{"There is no message 0."}},
+		{"/mail read -1", []string{"There is no message -1."}},
+
+		// Targets are matched by name prefix in name order
+		{"/hug al", []string{"You hug Albert."}},
+		{"/smile 2.al", []string{"You smile at Alfred."}},
+		{"/wave 1.alice", []string{"You can't do that to yourself."}},
+		{"/bow 4.al", []string{"You don't see them here."}},
+		{"/hug 0.al", []string{`"0" is not a valid position.`, "Usage: /hug <target>"}},
+		{"/hug all.al", []string{"You hug Albert.", "You hug Alfred."}},
+		{"/wave all.bo", []string{"You don't see them here."}},
+		{"/follow all.al", []string{"You can only follow one player at a time."}},
+		{"/hug x.al", []string{`"x" is not a valid position.`, "Usage: /hug <target>"}},
+		{"/hug al.", []string{`Nothing named after "al.".`, "Usage: /hug <target>"}},
+	}
+	for _, test := range tests {
+		alice.Send(test.input)
+		responses := alice.ExpectLines(len(test.expected))
+		for i, expected := range test.expected {
+			if responses[i] != expected {
+				t.Errorf("Unexpected response %d to %q: got %s, want %s", i+1, test.input, responses[i], expected)
+			}
+		}
+	}
+}
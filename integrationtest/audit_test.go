@@ -0,0 +1,81 @@
+package integrationtest
+
+import (
+	"strings"
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestAuditLog(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t, game.WithOwner("Alice", "secret"))
+
+	alice := s.Connect()
+	bob := s.Connect()
+
+	alice.Send("Alice")
+	alice.ExpectEventually("Password:")
+	alice.Send("secret")
+	alice.ExpectEventually("Alice has joined the room.")
+
+	// Taking a name that is in use is refused and recorded
+	bob.Send("Alice")
+	bob.ExpectEventually("Username 'Alice' is already taken.")
+	bob.Send("Bob")
+	bob.ExpectEventually("Bob has joined the room.")
+	alice.ExpectEventually("Bob has joined the room.")
+	bob.Send("/password hunter22")
+	bob.ExpectEventually("Your password has been set.")
+
+	alice.Send("/role Bob builder")
+	alice.ExpectEventually("Bob is now a builder.")
+
+	// Players cannot read the audit log
+	bob.Send("/audit")
+	bob.ExpectEventually("Unknown command: audit")
+
+	alice.Send("/audit bob")
+	lines := alice.ExpectEventually("role Bob")
+	expected := []string{
+		"Bob login: player (127.0.0.1)",
+		"Bob password Bob (127.0.0.1)",
+		"Alice role Bob: player to builder (127.0.0.1)",
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Unexpected /audit bob response: %v", lines)
+	}
+	for i, suffix := range expected {
+		if !strings.HasSuffix(lines[i], suffix) {
+			t.Errorf("Unexpected audit entry %d: got %s, want it to end with %s", i+1, lines[i], suffix)
+		}
+	}
+
+	alice.Send("/quit")
+	alice.ExpectEventually("Goodbye!")
+
+	// The audit log survives a restart
+	s = s.Restart()
+
+	// So are wrong passwords
+	mallory := s.Connect()
+	mallory.Send("Alice")
+	mallory.ExpectEventually("Password:")
+	mallory.Send("guess")
+	mallory.ExpectEventually("Wrong password.")
+	alice = s.LoginWithPassword("Alice", "secret")
+
+	alice.Send("/audit login-refused")
+	lines = alice.ExpectLines(2)
+	if !strings.HasSuffix(lines[0], "login-refused Alice: name in use (127.0.0.1)") {
+		t.Errorf("Unexpected /audit login-refused response: %v", lines)
+	}
+	if !strings.HasSuffix(lines[1], "login-refused Alice: wrong password (127.0.0.1)") {
+		t.Errorf("Unexpected /audit login-refused response: %v", lines)
+	}
+	alice.Send("/audit command")
+	if response := alice.ExpectLines(1)[0]; !strings.HasSuffix(response, "Alice command: /role Bob builder (127.0.0.1)") {
+		t.Errorf("Unexpected /audit command response: %s", response)
+	}
+}
@@ -0,0 +1,98 @@
+package integrationtest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestSlowClientIsDisconnected(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t, game.WithInputRateLimit(0, 0))
+
+	// Both players read their connections themselves, as Bob must stop
+	alice := dialAndJoin(t, s, "Alice")
+	defer alice.Close()
+	bob := dialAndJoin(t, s, "Bob")
+	defer bob.Close()
+
+	// Bob stops reading while Alice talks far more than he can take
+	line := strings.Repeat("x", 4000)
+	go func() {
+		for i := 0; i < 8000; i++ {
+			if _, err := fmt.Fprintf(alice, "%d %s\n", i, line); err != nil {
+				return
+			}
+			if i%8 == 0 {
+				time.Sleep(time.Millisecond)
+			}
+		}
+	}()
+	// Alice reads as fast as she can, so only Bob falls behind
+	left := make(chan bool)
+	go func() {
+		reader := bufio.NewReaderSize(alice, 1<<20)
+		for {
+			response, err := reader.ReadString('\n')
+			if err != nil {
+				close(left)
+				return
+			}
+			if strings.TrimSpace(response) == "Bob has left the room." {
+				left <- true
+				return
+			}
+		}
+	}()
+	select {
+	case ok := <-left:
+		if !ok {
+			t.Fatal("Alice was disconnected instead of Bob")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("Bob was never disconnected")
+	}
+
+	// Bob gets what was sent before he fell behind, then the reason
+	bob.SetReadDeadline(time.Now().Add(10 * time.Second))
+	reader := bufio.NewReader(bob)
+	var last string
+	for {
+		response, err := reader.ReadString('\n')
+		if err != nil {
+			break
+		}
+		last = strings.TrimSpace(response)
+	}
+	if last != "You are being disconnected because your connection cannot keep up." {
+		t.Errorf("Unexpected last line for Bob: %.80s", last)
+	}
+}
+
+// dialAndJoin logs in over a plain connection, returning once the player
+// has joined the room. The line it waits for is the last one it reads.
+func dialAndJoin(t *testing.T, s *mudtest.Server, name string) net.Conn {
+	conn, err := net.Dial("tcp", s.Addr)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	fmt.Fprintf(conn, "%s\n", name)
+	joined := name + " has joined the room.\n"
+	var read []byte
+	conn.SetReadDeadline(time.Now().Add(mudtest.Timeout))
+	defer conn.SetReadDeadline(time.Time{})
+	for !strings.HasSuffix(string(read), joined) {
+		b := make([]byte, 1)
+		if _, err := conn.Read(b); err != nil {
+			t.Fatalf("%s never joined: %v", name, err)
+		}
+		read = append(read, b[0])
+	}
+	return conn
+}
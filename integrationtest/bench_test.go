@@ -0,0 +1,192 @@
+package integrationtest
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mud/game"
+	"mud/logging"
+)
+
+// simulatedSessions is how many players the benchmarks simulate.
+const simulatedSessions = 5000
+
+// simulatedRoutes spreads the simulated players over the rooms of the
+// built-in world: player i walks routes[i%len(routes)] from the Lobby.
+var simulatedRoutes = [][]string{
+	nil,
+	{"north"},
+	{"north", "east"},
+	{"north", "west"},
+	{"north", "west", "up"},
+	{"north", "north"},
+}
+
+var opposite = map[string]string{"north": "south", "south": "north", "east": "west", "west": "east", "up": "down", "down": "up"}
+
+// simulatedPlayer is a session driven straight through the game's input
+// channel, without a network connection.
+type simulatedPlayer struct {
+	index int
+	id    string
+	name  string
+	route []string
+	walks int // steps taken by BenchmarkWalk
+
+	welcomes     atomic.Int64 // "Welcome, <name>!" lines received
+	descriptions atomic.Int64 // room descriptions received
+	says         atomic.Int64 // the player's own /say echoed back
+	whispers     atomic.Int64 // whispers received
+	disconnected atomic.Bool
+}
+
+// previous returns the player who logged in before this one, who is in
+// another room.
+func (p *simulatedPlayer) previous() *simulatedPlayer {
+	return simulated[(p.index+len(simulated)-1)%len(simulated)]
+}
+
+// drain reads the player's output as a client would, counting what the
+// benchmarks wait for.
+func (p *simulatedPlayer) drain(output <-chan game.OutputEvent) {
+	welcome, said := fmt.Sprintf("Welcome, %s!", p.name), p.name+" says:"
+	for event := range output {
+		p.welcomes.Add(int64(strings.Count(event.Message, welcome)))
+		p.descriptions.Add(int64(strings.Count(event.Message, "\nExits: ")))
+		p.says.Add(int64(strings.Count(event.Message, said)))
+		p.whispers.Add(int64(strings.Count(event.Message, " whispers: ")))
+	}
+	p.disconnected.Store(true)
+}
+
+var (
+	simulationOnce sync.Once
+	simulation     *game.Game
+	simulated      []*simulatedPlayer
+)
+
+// simulate returns a game with simulatedSessions players logged in and
+// spread over the world, shared by every benchmark.
+func simulate(b *testing.B) (*game.Game, []*simulatedPlayer) {
+	simulationOnce.Do(func() {
+		logging.Setup(io.Discard, false, slog.LevelError)
+		simulation = game.NewGame(game.WithInputRateLimit(0, 0))
+		input := simulation.GetInputChannel()
+		for i := 0; i < simulatedSessions; i++ {
+			p := &simulatedPlayer{
+				index: i,
+				id:    fmt.Sprintf("10.0.%d.%d:4000", i/256, i%256),
+				name:  fmt.Sprintf("Sim%d", i),
+				route: simulatedRoutes[i%len(simulatedRoutes)],
+			}
+			created := make(chan bool)
+			input <- game.InputEvent{SessionID: p.id, Kind: game.InputConnect, ResponseChan: created}
+			if !<-created {
+				b.Fatalf("Failed to create session %s", p.id)
+			}
+			output, _ := simulation.GetOutputChannel(p.id)
+			go p.drain(output)
+
+			// Walk off straight away so the Lobby does not fill up
+			simulation.SubmitInput(game.InputEvent{SessionID: p.id, Input: p.name})
+			for _, direction := range p.route {
+				simulation.SubmitInput(game.InputEvent{SessionID: p.id, Input: "/" + direction})
+			}
+			simulated = append(simulated, p)
+		}
+		waitFor(b, simulated, func(p *simulatedPlayer) bool {
+			return p.welcomes.Load() == 1 && p.descriptions.Load() == int64(len(p.route))
+		})
+	})
+	return simulation, simulated
+}
+
+// waitFor waits until done holds for every player.
+func waitFor(b *testing.B, players []*simulatedPlayer, done func(p *simulatedPlayer) bool) {
+	deadline := time.Now().Add(5 * time.Minute)
+	for _, p := range players {
+		for !done(p) {
+			if p.disconnected.Load() {
+				b.Fatalf("%s was disconnected", p.name)
+			}
+			if time.Now().After(deadline) {
+				b.Fatalf("%s did not get the output it was waiting for", p.name)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+}
+
+// benchmarkInput sends b.N lines of input, spread over all the players,
+// and waits until the responses counted by count for each player have
+// arrived.
+func benchmarkInput(b *testing.B, line func(p *simulatedPlayer, n int) string, count func(p *simulatedPlayer) *atomic.Int64) {
+	g, players := simulate(b)
+	expected := make([]int64, len(players))
+	for i, p := range players {
+		expected[i] = count(p).Load()
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		p := players[i%len(players)]
+		g.SubmitInput(game.InputEvent{SessionID: p.id, Input: line(p, int(expected[i%len(players)]))})
+		expected[i%len(players)]++
+	}
+	for i, p := range players {
+		waitFor(b, []*simulatedPlayer{p}, func(p *simulatedPlayer) bool { return count(p).Load() >= expected[i] })
+	}
+	b.StopTimer()
+	b.ReportMetric(float64(b.N)/b.Elapsed().Seconds(), "commands/s")
+}
+
+// BenchmarkLook measures commands that only concern the player.
+func BenchmarkLook(b *testing.B) {
+	benchmarkInput(b,
+		func(*simulatedPlayer, int) string { return "/look" },
+		func(p *simulatedPlayer) *atomic.Int64 { return &p.descriptions })
+}
+
+// BenchmarkSay measures chat heard by everyone in the room.
+func BenchmarkSay(b *testing.B) {
+	benchmarkInput(b,
+		func(p *simulatedPlayer, n int) string { return fmt.Sprintf("/say hello %d", n) },
+		func(p *simulatedPlayer) *atomic.Int64 { return &p.says })
+}
+
+// BenchmarkWhisper measures private messages between players in
+// different rooms, which go through the actor of the recipient's room.
+func BenchmarkWhisper(b *testing.B) {
+	benchmarkInput(b,
+		func(p *simulatedPlayer, n int) string {
+			return fmt.Sprintf("/whisper %s hello %d", p.previous().name, n)
+		},
+		func(p *simulatedPlayer) *atomic.Int64 { return &p.previous().whispers })
+}
+
+// BenchmarkWalk measures players moving between rooms, which hands them
+// from one room's actor to another's.
+func BenchmarkWalk(b *testing.B) {
+	benchmarkInput(b,
+		func(p *simulatedPlayer, _ int) string {
+			// Step back along the last leg of the route and return, so
+			// players stay spread out
+			there, back := "north", "south"
+			if len(p.route) > 0 {
+				back = p.route[len(p.route)-1]
+				there = opposite[back]
+			}
+			p.walks++
+			if p.walks%2 == 1 {
+				return "/" + there
+			}
+			return "/" + back
+		},
+		func(p *simulatedPlayer) *atomic.Int64 { return &p.descriptions })
+}
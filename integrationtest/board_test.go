@@ -0,0 +1,74 @@
+package integrationtest
+
+import (
+	"strings"
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestBoard(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	alice.Send("/board")
+	alice.ExpectLine("The General board is empty.")
+
+	alice.Send("/board post Guild meeting")
+	alice.ExpectLines(2)
+	alice.Send("Tonight at the fountain.")
+	alice.Send(".")
+	alice.ExpectLine(`You post "Guild meeting" on the General board.`)
+	bob.ExpectLine("Alice posts a message on the General board.")
+
+	bob.Send("/board read 1")
+	responses := bob.ExpectLines(4)
+	if responses[0] != "1. Guild meeting" || !strings.HasPrefix(responses[1], "By Alice on ") || responses[3] != "Tonight at the fountain." {
+		t.Errorf("Unexpected post: %v", responses)
+	}
+
+	bob.Send("/board delete 1")
+	bob.ExpectLine("You can only delete your own posts.")
+
+	bob.Send("/board place Secret board")
+	bob.ExpectLine("Only builders can place and remove boards.")
+
+	// Posts survive a restart
+	s = s.Restart()
+	alice = s.Login("Alice")
+
+	alice.Send("/board list")
+	responses = alice.ExpectLines(2)
+	if responses[0] != "The General board:" || !strings.HasSuffix(responses[1], "Guild meeting") {
+		t.Errorf("Unexpected board listing after restart: %v", responses)
+	}
+}
+
+func TestBoardPermissions(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t, game.WithOwner("Alice", "secret"))
+
+	alice := s.LoginWithPassword("Alice", "secret")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	alice.Send("/board remove")
+	alice.ExpectLine("You remove the General board.")
+
+	// Misspelt permissions would lock everyone out, owner included
+	alice.Send("/board place Staff moderate-board")
+	response := alice.ExpectLines(1)[0]
+	if !strings.HasPrefix(response, "Unknown permission: moderate-board. Known permissions: ") || !strings.Contains(response, "moderate-boards") {
+		t.Errorf("Unexpected response to an unknown permission: %q", response)
+	}
+	alice.Send("/board place Staff build moderate-boards")
+	alice.ExpectLine("You place the Staff here.")
+
+	bob.Send("/board")
+	bob.ExpectLine("You are not allowed to read the Staff.")
+}
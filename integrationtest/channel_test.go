@@ -0,0 +1,54 @@
+package integrationtest
+
+import (
+	"testing"
+
+	"mud/mudtest"
+)
+
+func TestChannels(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	steps := []struct {
+		client *mudtest.Client
+		input  string
+		alice  []string
+		bob    []string
+	}{
+		{alice, "/gossip hello all", []string{"[gossip] Alice: hello all"}, []string{"[gossip] Alice: hello all"}},
+		{bob, "/channel leave gossip", []string{"[gossip] Bob has left the channel."}, []string{"You left gossip."}},
+		{alice, "/gos second", []string{"[gossip] Alice: second"}, nil},
+		{bob, "/channel join gossip", []string{"[gossip] Bob has joined the channel."}, []string{"You joined gossip.", "Recent messages:", "[gossip] Alice: hello all", "[gossip] Alice: second"}},
+		{alice, "/channel create guild", []string{"You created the private channel guild. Use /channel invite guild <player> to let others join.", "You joined guild."}, nil},
+		{bob, "/channel join guild", nil, []string{"There is no channel named guild."}},
+		{alice, "/channel invite guild Bob", []string{"You invited Bob to guild."}, []string{"Alice invited you to the channel guild. Type /channel join guild to join."}},
+		{bob, "/channel join guild", []string{"[guild] Bob has joined the channel."}, []string{"You joined guild."}},
+		{alice, "/channel mute guild Bob", []string{"[guild] Bob was muted by Alice."}, []string{"[guild] Bob was muted by Alice."}},
+		{bob, "/guild hi", nil, []string{"You are muted on guild."}},
+		{bob, "/channel mute gossip Alice", nil, []string{"You cannot moderate gossip."}},
+	}
+	for _, step := range steps {
+		step.client.Send(step.input)
+		for _, check := range []struct {
+			name     string
+			client   *mudtest.Client
+			expected []string
+		}{{"Alice", alice, step.alice}, {"Bob", bob, step.bob}} {
+			if len(check.expected) == 0 {
+				continue
+			}
+			responses := check.client.ExpectLines(len(check.expected))
+			for i, expected := range check.expected {
+				if responses[i] != expected {
+					t.Errorf("Unexpected response %d for %s to %q: got %s, want %s", i+1, check.name, step.input, responses[i], expected)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package integrationtest
+
+import (
+	"strings"
+	"testing"
+
+	"mud/mudtest"
+)
+
+func TestFinger(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	bob.Send("/afk making tea")
+	bob.ExpectEventually("You are now AFK.")
+
+	// Online players show where they are and their AFK message
+	alice.Send("/finger bob")
+	lines := alice.ExpectEventually("Last login:")
+	if lines[0] != "Bob" || lines[1] != "Adventurer" || !strings.HasPrefix(lines[2], "Online in Lobby") || !strings.HasSuffix(lines[2], "AFK: making tea") {
+		t.Errorf("Unexpected /finger response: %v", lines)
+	}
+
+	// Whispering an AFK player tells the sender
+	alice.Send("/whisper Bob are you there?")
+	alice.ExpectEventually("Bob is AFK: making tea")
+
+	// Doing something clears the AFK flag
+	bob.Send("/look")
+	bob.ExpectEventually("You are no longer AFK.")
+
+	bob.Send("/quit")
+	alice.ExpectEventually("Bob has left the room.")
+
+	// Offline players can be looked up too
+	alice.Send("/whois Bob")
+	lines = alice.ExpectEventually("Last login:")
+	if lines[2] != "Offline" {
+		t.Errorf("Unexpected /whois response for an offline player: %v", lines)
+	}
+
+	alice.Send("/finger Nobody")
+	alice.ExpectLine("User 'Nobody' not found.")
+}
@@ -0,0 +1,98 @@
+package integrationtest
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mud/game"
+	"mud/mudtest"
+	"mud/telnet"
+)
+
+func TestLongLinesAreRefused(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	alice.Send("/say " + strings.Repeat("x", 64<<10))
+	alice.ExpectLine("That line was too long, so it was ignored. Lines can be up to 4096 characters.")
+	alice.Send("/say " + strings.Repeat("y", game.MaxLineLength-5))
+	alice.ExpectLine("Alice says: " + strings.Repeat("y", game.MaxLineLength-5))
+}
+
+func TestFloodingIsTurnedDownAtTheConnection(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+	for i := 0; i < 30; i++ {
+		bob.Send(fmt.Sprintf("/say spam %d", i))
+	}
+	bob.ExpectEventually("You are typing too fast, so that was ignored.")
+
+	// The lines over the limit never reach the room
+	alice.Send("/say done")
+	lines := alice.ExpectEventually("Alice says: done")
+	if spam := len(lines) - 1; spam >= 30 {
+		t.Errorf("Expected some of the spam to be dropped, got all %d lines", spam)
+	}
+}
+
+func TestConnectionsPerIPAreLimited(t *testing.T) {
+	t.Parallel()
+	g := game.NewGame(game.WithDataDir(t.TempDir()))
+	defer g.Shutdown()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := telnet.NewServer(g)
+	server.MaxConnectionsPerIP = 2
+	go server.Serve(listener)
+	defer listener.Close()
+
+	// connect returns the first line the server sends, and the connection
+	connect := func() (string, net.Conn) {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		conn.SetReadDeadline(time.Now().Add(mudtest.Timeout))
+		line, _ := bufio.NewReader(conn).ReadString('\n')
+		return strings.TrimSpace(line), conn
+	}
+
+	first, conn := connect()
+	defer conn.Close()
+	second, other := connect()
+	defer other.Close()
+	if first != "Welcome to the MUD server!" || second != first {
+		t.Fatalf("Expected the first two connections to be welcomed, got %q and %q", first, second)
+	}
+	refused, extra := connect()
+	extra.Close()
+	if refused != "There are too many connections from your address. Please try again later." {
+		t.Errorf("Expected the third connection to be refused, got %q", refused)
+	}
+
+	// Once a connection closes, another may take its place
+	other.Close()
+	deadline := time.Now().Add(mudtest.Timeout)
+	for {
+		line, conn := connect()
+		conn.Close()
+		if line == first {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("A new connection was still refused after one closed, got %q", line)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
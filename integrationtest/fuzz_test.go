@@ -0,0 +1,108 @@
+package integrationtest
+
+import (
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+// fuzzSeeds are inputs that have broken, or could break, the handling of
+// what clients send.
+var fuzzSeeds = []string{
+	"",
+	"/",
+	"/\n/ \n//\n",
+	"Alice\n/\n",
+	"\xff\xfb\x18\xff\xfd\x01\xff\xfa\x18\x00xterm\xff\xf0Alice\n/look\n",
+	"Al\xffice\n",
+	"\xff\xf4\xff\xfd\x06",
+	"Alice\r\n/say caf\xe9 \xc3\x28\r\n",
+	"Alice\n/say half a line",
+	"Alice\n/whisper\n/whisper Alice\n/reply\n/tells\n",
+	"Alice\n/mail Alice\nSubject\nA letter\n.\n/mail\n",
+	"Alice\n/alias x /x\n/x\n",
+	"Alice\n/group invite Alice\n/follow Alice\n/quit\n",
+	"Alice\n/who level 9-1 sort\n/finger\n/help help help\n",
+	"Alice\n" + strings.Repeat("x", 64<<10) + "\n",
+	"Alice\n" + strings.Repeat("/say ", 1000) + "\n",
+}
+
+// FuzzTelnetInput sends arbitrary bytes to the telnet server as a client
+// would, and checks the server hangs up once the client stops sending and
+// the session leaves the game.
+func FuzzTelnetInput(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add([]byte(seed))
+	}
+	s := mudtest.NewServer(f)
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		conn, err := net.Dial("tcp", s.Addr)
+		if err != nil {
+			t.Fatalf("Failed to connect: %v", err)
+		}
+		defer conn.Close()
+		id := conn.LocalAddr().String()
+
+		// The server may hang up before it has read everything, so a
+		// failed write is not a problem
+		conn.SetWriteDeadline(time.Now().Add(mudtest.Timeout))
+		conn.Write(data)
+		conn.(*net.TCPConn).CloseWrite()
+
+		conn.SetReadDeadline(time.Now().Add(mudtest.Timeout))
+		if _, err := io.Copy(io.Discard, conn); err != nil {
+			t.Fatalf("The server did not hang up after the client stopped sending: %v", err)
+		}
+		deadline := time.Now().Add(mudtest.Timeout)
+		for {
+			if _, exists := s.Game.GetOutputChannel(id); !exists {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("The session of %s was still in the game after the connection closed", id)
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+}
+
+// FuzzGameInput feeds arbitrary lines straight to a game, without the
+// telnet server tidying them up first, and checks the game finishes
+// handling each one and forgets the player once they disconnect.
+func FuzzGameInput(f *testing.F) {
+	for _, seed := range fuzzSeeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		sim := game.NewSimulation(game.WithDataDir(t.TempDir()))
+		defer sim.Game.Shutdown()
+
+		// A simulation waits for the game to finish with each line, and
+		// fails if it does not
+		for _, line := range strings.Split(input, "\n") {
+			if sim.Send("Fuzz", line) != nil || !sim.Connected("Fuzz") {
+				break
+			}
+		}
+		if err := sim.Disconnect("Fuzz"); err != nil {
+			t.Fatal(err)
+		}
+		if sim.Connected("Fuzz") {
+			t.Fatal("The session was still in the game after disconnecting")
+		}
+
+		if err := sim.Run("Check> Check\nCheck> /who"); err != nil {
+			t.Fatal(err)
+		}
+		if transcript := sim.Transcript(); !strings.Contains(transcript, "Players online: 1\n") {
+			t.Fatalf("Expected Check to be the only player left, got:\n%s", transcript)
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package integrationtest
+
+import (
+	"strings"
+	"testing"
+
+	"mud/mudtest"
+)
+
+func TestGroupFollowAndChat(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	alice.Send("/group invite Bob")
+	alice.ExpectEventually("You invite Bob to join your group.")
+	bob.ExpectEventually("Alice invites you to join their group. Type /group accept to join.")
+
+	bob.Send("/group accept")
+	alice.ExpectEventually("[group] Bob joins the group.")
+	bob.ExpectEventually("[group] Bob joins the group.")
+
+	// Bob follows Alice when she walks north
+	alice.Send("/n")
+	responses := alice.ExpectLines(4)
+	if responses[0] != "Town Square" || responses[3] != "Bob arrives from the south." {
+		t.Errorf("Unexpected responses for Alice walking north: %v", responses)
+	}
+	responses = bob.ExpectLines(6)
+	if responses[0] != "Alice leaves north." || responses[1] != "You follow Alice north." || responses[2] != "Town Square" || responses[5] != "Also here: Alice" {
+		t.Errorf("Unexpected responses for Bob following Alice: %v", responses)
+	}
+
+	alice.Send("/gtell meet at the tavern")
+	alice.ExpectLine("[group] Alice: meet at the tavern")
+	bob.ExpectLine("[group] Alice: meet at the tavern")
+
+	bob.Send("/who")
+	if response := bob.ExpectEventually("Bob "); strings.Count(strings.Join(response, "\n"), "[Alice's group]") != 2 {
+		t.Errorf("Unexpected /who output for a group: %v", response)
+	}
+
+	bob.Send("/group leave")
+	responses = alice.ExpectLines(2)
+	if responses[0] != "[group] Bob leaves the group." || responses[1] != "[group] The group has been disbanded." {
+		t.Errorf("Unexpected responses for Alice when Bob left: %v", responses)
+	}
+	if response := bob.ExpectEventually("You leave the group."); len(response) != 2 {
+		t.Errorf("Unexpected responses for Bob leaving: %v", response)
+	}
+}
+
+func TestDeclinedGroupInvitation(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	alice.Send("/group invite Bob")
+	alice.ExpectLine("You invite Bob to join your group.")
+	bob.ExpectEventually("Alice invites you to join their group.")
+
+	// Until someone joins, there is no group
+	alice.Send("/group")
+	alice.ExpectLine("You are not in a group.")
+
+	bob.Send("/group decline")
+	bob.ExpectLine("You decline Alice's invitation.")
+	alice.ExpectLine("Bob declines your invitation.")
+
+	alice.Send("/who")
+	if response := alice.ExpectEventually("Bob "); strings.Contains(strings.Join(response, "\n"), "group]") {
+		t.Errorf("Unexpected group in /who after a declined invitation: %v", response)
+	}
+	bob.Send("/group accept")
+	bob.ExpectLine("Nobody has invited you to a group.")
+}
+
+func TestGroupLeaderLeaving(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	charlie := s.Login("Charlie")
+	alice.ExpectEventually("Charlie has joined the room.")
+	bob.ExpectEventually("Charlie has joined the room.")
+
+	alice.Send("/group invite Bob")
+	bob.ExpectEventually("Alice invites you to join their group.")
+	bob.Send("/group accept")
+	alice.Send("/group invite Charlie")
+	charlie.ExpectEventually("Alice invites you to join their group.")
+	charlie.Send("/group accept")
+	alice.ExpectEventually("[group] Charlie joins the group.")
+	bob.ExpectEventually("[group] Charlie joins the group.")
+	charlie.ExpectEventually("[group] Charlie joins the group.")
+
+	alice.Send("/group leave")
+	bob.ExpectEventually("[group] Bob now leads the group.")
+	charlie.ExpectEventually("[group] Bob now leads the group.")
+
+	// The new leader follows nobody, and the others follow them
+	bob.Send("/follow")
+	bob.ExpectLine("You are not following anyone.")
+	charlie.Send("/follow")
+	charlie.ExpectLine("You are following Bob.")
+}
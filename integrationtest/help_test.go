@@ -3,23 +3,20 @@ package integrationtest
 import (
 	"strings"
 	"testing"
+
+	"mud/mudtest"
 )
 
 func TestHelpCommand(t *testing.T) {
-	startServer(t)
-	defer stopServer()
+	t.Parallel()
+	s := mudtest.NewServer(t)
 
 	// Connect a user
-	conn := connectTelnet(t)
-	defer conn.Close()
-
-	// Set name for user
-	sendCommand(t, conn, "Alice")
-	readResponses(t, conn, 2) // Welcome + Who are you?
+	alice := s.Login("Alice")
 
 	// Test general help command
-	sendCommand(t, conn, "/help")
-	response := strings.Join(readResponses(t, conn, 1), "\n")
+	alice.Send("/help")
+	response := strings.Join(alice.ExpectEventually("Type /help <command>"), "\n")
 
 	// Check that the response includes "Available commands:"
 	if !strings.Contains(response, "Available commands:") {
@@ -27,18 +24,38 @@ func TestHelpCommand(t *testing.T) {
 	}
 
 	// Check that all expected commands are listed
-	expectedCommands := []string{"/whisper", "/who", "/help", "/quit"}
+	expectedCommands := []string{"/say", "/whisper", "/who", "/help", "/quit"}
 	for _, cmd := range expectedCommands {
 		if !strings.Contains(response, cmd) {
 			t.Errorf("Help response doesn't contain expected command '%s': %s", cmd, response)
 		}
 	}
 
+	// Check that commands are grouped by category
+	expectedCategories := map[string][]string{
+		"Communication": {"/say", "/whisper"},
+		"Information":   {"/help", "/who"},
+		"System":        {"/quit"},
+	}
+	for category, commands := range expectedCategories {
+		var line string
+		for _, l := range strings.Split(response, "\n") {
+			if strings.HasPrefix(strings.TrimSpace(l), category+":") {
+				line = l
+			}
+		}
+		for _, cmd := range commands {
+			if !strings.Contains(line, cmd) {
+				t.Errorf("Help response doesn't list '%s' under '%s': %s", cmd, category, response)
+			}
+		}
+	}
+
 	// Test help for specific commands
-	specificCommands := []string{"whisper", "who", "help", "quit"}
+	specificCommands := []string{"say", "whisper", "who", "help", "quit"}
 	for _, cmd := range specificCommands {
-		sendCommand(t, conn, "/help "+cmd)
-		response = strings.Join(readResponses(t, conn, 2), "\n")
+		alice.Send("/help " + cmd)
+		response = strings.Join(alice.ExpectEventually("Usage:"), "\n")
 
 		// Check that the response includes "Usage:" for each command
 		if !strings.Contains(response, "Usage:") {
@@ -52,10 +69,6 @@ func TestHelpCommand(t *testing.T) {
 	}
 
 	// Test help for non-existent command
-	sendCommand(t, conn, "/help nonexistentcommand")
-	response = readResponses(t, conn, 1)[0]
-	expectedErrorResponse := "Unknown command: nonexistentcommand"
-	if response != expectedErrorResponse {
-		t.Errorf("Unexpected error response for non-existent command: got %s, want %s", response, expectedErrorResponse)
-	}
-}
\ No newline at end of file
+	alice.Send("/help nonexistentcommand")
+	alice.ExpectLine("Unknown command: nonexistentcommand")
+}
@@ -0,0 +1,36 @@
+package integrationtest
+
+import (
+	"testing"
+
+	"mud/mudtest"
+)
+
+func TestIgnore(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	alice.Send("/ignore Bob")
+	alice.ExpectLine("You are now ignoring bob.")
+
+	// Bob's messages no longer reach Alice
+	bob.Send("/say anyone here?")
+	bob.ExpectLines(1)
+	bob.Send("/gossip hello")
+	bob.ExpectLines(1)
+	bob.Send("/whisper Alice psst")
+	bob.ExpectLine("Alice is ignoring you.")
+
+	alice.Send("/ignore")
+	alice.ExpectLine("You are ignoring: bob")
+
+	alice.Send("/unignore bob")
+	alice.ExpectLine("You are no longer ignoring bob.")
+
+	bob.Send("/say back again")
+	alice.ExpectLine("Bob says: back again")
+}
@@ -0,0 +1,75 @@
+package integrationtest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"mud/mudtest"
+)
+
+func TestDroppedConnection(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	// Bob's connection goes away without a /quit
+	bob.Close()
+	alice.ExpectEventually("Bob has left the room.")
+
+	// The name is free again straight away
+	bob = s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+}
+
+func TestConnectionChurn(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	watcher := s.Login("Watcher")
+
+	// Players come and go at the same time, half of them with /quit and
+	// half by dropping the connection.
+	const players = 20
+	var wg sync.WaitGroup
+	for i := 0; i < players; i++ {
+		player := s.Connect()
+		name := fmt.Sprintf("Player%d", i)
+		player.Send(name)
+		if i%2 == 0 {
+			player.Send("/quit")
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := player.Await(name + " has joined the room."); err != nil {
+				t.Errorf("%s never joined: %v", name, err)
+				return
+			}
+			if i%2 == 1 {
+				player.Close()
+			} else if _, err := player.Await("Goodbye!"); err != nil {
+				t.Errorf("%s never got a goodbye: %v", name, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Once everyone has gone, only the watcher is left
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		watcher.Send("/who")
+		who := watcher.ExpectEventually("Players online:")
+		if who[len(who)-1] == "Players online: 1" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Players were left behind after disconnecting: %v", who)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
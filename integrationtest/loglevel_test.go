@@ -0,0 +1,52 @@
+package integrationtest
+
+import (
+	"log/slog"
+	"testing"
+
+	"mud/game"
+	"mud/logging"
+	"mud/mudtest"
+)
+
+func TestLogLevels(t *testing.T) {
+	// Levels are shared by the whole process, so this test runs on its own
+	// and puts them back afterwards
+	if err := logging.SetLevel("chat", slog.LevelWarn); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		logging.SetLevel("chat", slog.LevelInfo)
+		logging.SetLevel("telnet", slog.LevelInfo)
+	})
+	s := mudtest.NewServer(t, game.WithOwner("Alice", "secret"))
+
+	alice := s.LoginWithPassword("Alice", "secret")
+
+	alice.Send("/loglevel")
+	lines := alice.ExpectEventually("telnet:")
+	expected := map[string]bool{"chat: WARN": false, "game: INFO": false, "telnet: INFO": false}
+	for _, line := range lines {
+		if _, exists := expected[line]; exists {
+			expected[line] = true
+		}
+	}
+	for line, found := range expected {
+		if !found {
+			t.Errorf("Expected %q in the log levels, got %v", line, lines)
+		}
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"/loglevel telnet debug", "telnet now logs at debug."},
+		{"/loglevel nowhere debug", "There is no subsystem called nowhere."},
+		{"/loglevel telnet loud", "The level must be debug, info, warn or error."},
+	}
+	for _, test := range tests {
+		alice.Send(test.input)
+		alice.ExpectLine(test.expected)
+	}
+}
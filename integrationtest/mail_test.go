@@ -0,0 +1,100 @@
+package integrationtest
+
+import (
+	"strings"
+	"testing"
+
+	"mud/mudtest"
+)
+
+func TestMail(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	// Alice writes a two-line message; commands typed in the editor are text
+	alice.Send("/mail send Bob Hello there")
+	responses := alice.ExpectLines(2)
+	if responses[0] != "Writing to Bob: Hello there" {
+		t.Errorf("Unexpected editor title: %s", responses[0])
+	}
+	alice.Send("How are you?")
+	alice.Send("/who is around?")
+	alice.Send(".")
+	alice.ExpectLine("Mail sent to Bob.")
+	bob.ExpectLine("You have new mail from Alice.")
+
+	bob.Send("/mail list")
+	responses = bob.ExpectLines(2)
+	if responses[0] != "Your mail:" || !strings.HasPrefix(responses[1], "N  1. Alice") || !strings.HasSuffix(responses[1], "Hello there") {
+		t.Errorf("Unexpected mail list: %v", responses)
+	}
+
+	bob.Send("/mail read 1")
+	responses = bob.ExpectLines(6)
+	if responses[0] != "From: Alice" || responses[2] != "Subject: Hello there" || responses[4] != "How are you?" || responses[5] != "/who is around?" {
+		t.Errorf("Unexpected mail: %v", responses)
+	}
+
+	bob.Send("/mail read 2")
+	bob.ExpectLine("There is no message 2.")
+
+	bob.Send("/mail delete 1")
+	bob.ExpectLine("Message 1 deleted.")
+
+	// Aborting the editor sends nothing
+	alice.Send("/mail send Bob Never mind")
+	alice.ExpectLines(2)
+	alice.Send("oops")
+	alice.Send("~q")
+	alice.ExpectLine("Aborted.")
+
+	// Mail sent while Bob is away is announced when he logs in
+	bob.Send("/quit")
+	alice.ExpectEventually("Bob has left the room.")
+	alice.Send("/mail send Bob Later")
+	alice.ExpectLines(2)
+	alice.Send("See you.")
+	alice.Send(".")
+	alice.ExpectLines(1)
+
+	bob = s.Connect()
+	bob.Send("Bob")
+	bob.ExpectEventually("You have 1 unread mail message(s). Type /mail list to see them.")
+}
+
+func TestMailKeepsBlankLinesAndIndentation(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	body := []string{
+		"Dear Bob,",
+		"",
+		"Shopping list:",
+		"  - bread",
+		"  - milk",
+		"",
+		"Alice",
+	}
+	alice.Send("/mail send Bob Errands")
+	alice.ExpectLines(2)
+	for _, line := range body {
+		alice.Send(line)
+	}
+	alice.Send(".")
+	alice.ExpectLine("Mail sent to Bob.")
+	bob.ExpectLine("You have new mail from Alice.")
+
+	bob.Send("/mail read 1")
+	responses := bob.ExpectLines(4 + len(body))
+	if got := strings.Join(responses[4:], "\n"); got != strings.Join(body, "\n") {
+		t.Errorf("Unexpected mail body:\n%s", got)
+	}
+}
@@ -0,0 +1,112 @@
+package integrationtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+// fetchUntil polls url until its body contains text and returns the last
+// body fetched.
+func fetchUntil(t *testing.T, url, text string) string {
+	var body string
+	for i := 0; i < 50; i++ {
+		if response, err := http.Get(url); err == nil {
+			data, _ := io.ReadAll(response.Body)
+			response.Body.Close()
+			body = string(data)
+			if strings.Contains(body, text) {
+				return body
+			}
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("%s never contained %q, last got:\n%s", url, text, body)
+	return body
+}
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+	status := httptest.NewServer(s.Game.StatusHandler())
+	defer status.Close()
+
+	if body := fetchUntil(t, status.URL+"/healthz", "ok"); body != "ok\n" {
+		t.Errorf("Unexpected /healthz response: %q", body)
+	}
+
+	alice := s.Login("Alice")
+	alice.Send("/look")
+	alice.ExpectEventually("Lobby")
+
+	body := fetchUntil(t, status.URL+"/metrics", `mud_sessions{transport="telnet"} 1`)
+	for _, expected := range []string{
+		"# TYPE mud_input_events_total counter",
+		"mud_input_queue_depth 0",
+		"mud_output_dropped_total 0",
+		`mud_command_duration_seconds_count{command="look"} 1`,
+		`mud_command_duration_seconds_bucket{command="look",le="+Inf"} 1`,
+	} {
+		if !strings.Contains(body, expected) {
+			t.Errorf("Expected %q in the metrics, got:\n%s", expected, body)
+		}
+	}
+}
+
+// stallingWriter is a recording that blocks writing any output containing
+// marker until it is released, stalling whichever actor delivers it.
+type stallingWriter struct {
+	marker  string
+	release chan struct{}
+	once    sync.Once
+}
+
+func (w *stallingWriter) Write(p []byte) (int, error) {
+	if strings.Contains(string(p), `"kind":"output"`) && strings.Contains(string(p), w.marker) {
+		<-w.release
+	}
+	return len(p), nil
+}
+
+func (w *stallingWriter) Release() {
+	w.once.Do(func() { close(w.release) })
+}
+
+func TestHealthyStuckChannel(t *testing.T) {
+	t.Parallel()
+	w := &stallingWriter{marker: "stuck-here", release: make(chan struct{})}
+	s := mudtest.NewServer(t, game.WithRecording(w), game.WithStallTimeout(200*time.Millisecond))
+	t.Cleanup(w.Release)
+
+	alice := s.Login("Alice")
+	if err := s.Game.Healthy(); err != nil {
+		t.Fatalf("Expected a healthy game, got: %v", err)
+	}
+	alice.Send("/gossip stuck-here")
+
+	var err error
+	for i := 0; i < 50 && err == nil; i++ {
+		time.Sleep(100 * time.Millisecond)
+		err = s.Game.Healthy()
+	}
+	if err == nil || !strings.Contains(err.Error(), "channel gossip") {
+		t.Fatalf("Expected the gossip channel to be reported stuck, got: %v", err)
+	}
+
+	w.Release()
+	alice.ExpectEventually("[gossip] Alice: stuck-here")
+	for i := 0; i < 50 && err != nil; i++ {
+		time.Sleep(100 * time.Millisecond)
+		err = s.Game.Healthy()
+	}
+	if err != nil {
+		t.Errorf("Expected a healthy game once the channel recovered, got: %v", err)
+	}
+}
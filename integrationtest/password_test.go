@@ -0,0 +1,85 @@
+package integrationtest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestPasswords(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	s := mudtest.NewServer(t, game.WithDataDir(dir), game.WithOwner("Alice", "secret"))
+
+	// Nobody logs in as the owner without the owner password
+	mallory := s.Connect()
+	mallory.Send("Alice")
+	mallory.ExpectEventually("Password:")
+	mallory.Send("letmein")
+	mallory.ExpectLine("Wrong password.")
+	mallory.ExpectLine("Who are you?")
+
+	alice := s.LoginWithPassword("Alice", "secret")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	bob.Send("/password abc")
+	bob.ExpectLine("Passwords must be at least 6 characters long.")
+	bob.Send("/password hunter22")
+	bob.ExpectLine("Your password has been set.")
+	// Only the server's user can read the password hash
+	if info, err := os.Stat(filepath.Join(dir, "accounts", "bob.json")); err != nil {
+		t.Errorf("Failed to find Bob's account: %v", err)
+	} else if info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected Bob's account to be readable only by the server, got mode %v", info.Mode().Perm())
+	}
+	bob.Send("/password hunter33")
+	bob.ExpectLine("Give your current password, then the new one.")
+	bob.ExpectLine("Usage: /password [<current password>] <new password>")
+	bob.Send("/password wrong hunter33")
+	bob.ExpectLine("That is not your current password.")
+
+	alice.Send("/role Bob moderator")
+	alice.ExpectEventually("Bob is now a moderator.")
+	bob.Send("/quit")
+	bob.ExpectEventually("Goodbye!")
+
+	// Bob's name alone no longer gets anyone his role
+	mallory.Send("Bob")
+	mallory.ExpectEventually("Password:")
+	mallory.Send("hunter33")
+	mallory.ExpectLine("Wrong password.")
+	mallory.ExpectLine("Who are you?")
+
+	bob = s.LoginWithPassword("Bob", "hunter22")
+	bob.Send("/role Bob")
+	bob.ExpectLine("Unknown command: role")
+	bob.Send("/mute Mallory")
+	bob.ExpectLine("User 'Mallory' not found.")
+}
+
+func TestStaffWithoutPassword(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	account := []byte(`{"name": "Carol", "role": "admin"}`)
+	if err := os.MkdirAll(filepath.Join(dir, "accounts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "accounts", "carol.json"), account, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	s := mudtest.NewServer(t, game.WithDataDir(dir))
+
+	c := s.Connect()
+	c.Send("Carol")
+	c.ExpectEventually("Carol is a staff account without a password, so nobody can log in as Carol.")
+
+	// Without an owner password, nobody can be the owner either
+	s = mudtest.NewServer(t, game.WithOwner("Alice", ""))
+	c = s.Connect()
+	c.Send("Alice")
+	c.ExpectEventually("Alice is a staff account without a password, so nobody can log in as Alice.")
+}
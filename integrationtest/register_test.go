@@ -0,0 +1,70 @@
+package integrationtest
+
+import (
+	"fmt"
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+// permissionDJ is a permission only the commands in this test know about.
+const permissionDJ game.Permission = "dj"
+
+func TestRegisterCommandFromAnotherPackage(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t, game.WithOwner("Alice", "secret"), game.WithRolePermissions(game.RoleModerator, permissionDJ))
+
+	juggle := game.Command{
+		Name:     "juggle",
+		Aliases:  []string{"jug"},
+		Short:    "Juggle some balls.",
+		Category: "Fun",
+		Handler: func(g *game.Game, session *game.Session, args *game.Args) []game.OutputEvent {
+			return []game.OutputEvent{{SessionID: session.ID, Message: "You juggle."}}
+		},
+	}
+	play := game.Command{
+		Name:       "play",
+		Short:      "Play a song for the room.",
+		Args:       []game.ArgSpec{{Name: "song", Kind: game.ArgRest}},
+		Category:   "Fun",
+		Permission: permissionDJ,
+		Handler: func(g *game.Game, session *game.Session, args *game.Args) []game.OutputEvent {
+			return []game.OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("Now playing: %s", args.String("song"))}}
+		},
+	}
+	for _, command := range []game.Command{juggle, play} {
+		if err := s.Game.RegisterCommand(command); err != nil {
+			t.Fatalf("Failed to register /%s: %v", command.Name, err)
+		}
+	}
+	if err := s.Game.RegisterCommand(juggle); err == nil {
+		t.Error("Expected registering /juggle twice to fail")
+	}
+
+	alice := s.LoginWithPassword("Alice", "secret")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	bob.Send("/jug")
+	bob.ExpectLine("You juggle.")
+	bob.Send("/help juggle")
+	bob.ExpectLine("Juggle some balls.")
+	bob.ExpectLine("Usage: /juggle")
+	bob.ExpectLine("Aliases: jug")
+
+	// Only the roles granted the new permission can play
+	bob.Send("/play Smooth Jazz")
+	bob.ExpectLine("Unknown command: play. Did you mean /say?")
+	alice.Send("/play Smooth Jazz")
+	alice.ExpectLine("Now playing: Smooth Jazz")
+
+	bob.Send("/password hunter22")
+	bob.ExpectLine("Your password has been set.")
+	alice.Send("/role Bob moderator")
+	bob.ExpectEventually("Alice made you a moderator.")
+	bob.Send("/play")
+	bob.ExpectLine("Missing <song>.")
+	bob.ExpectLine("Usage: /play <song>")
+}
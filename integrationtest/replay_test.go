@@ -0,0 +1,141 @@
+package integrationtest
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+// recording collects a game's recording while the game writes to it.
+type recording struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (r *recording) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.Write(p)
+}
+
+func (r *recording) String() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buf.String()
+}
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+	var rec recording
+	s := mudtest.NewServer(t, game.WithRecording(&rec))
+
+	clients := s.Scenario(`
+		Alice< Bob has joined the room.
+		Alice> /say Hello everyone
+		Alice= Alice says: Hello everyone
+		Bob= Alice says: Hello everyone
+		Bob> /whisper Alice Just between us
+		Bob= You whispered to Alice: Just between us
+		Alice= Bob whispers: Just between us
+		Bob> /go north
+		Alice< Bob leaves
+		Bob> /nosuchcommand
+		Bob< Unknown command
+	`)
+	for _, name := range []string{"Alice", "Bob"} {
+		clients[name].Send("/quit")
+		clients[name].ExpectClosed()
+	}
+
+	records, err := game.ReadRecording(strings.NewReader(rec.String()))
+	if err != nil {
+		t.Fatalf("Failed to read the recording: %v", err)
+	}
+	kinds := make(map[string]int)
+	for _, record := range records {
+		kinds[record.Kind]++
+	}
+	if kinds[game.RecordConnect] != 2 || kinds[game.RecordInput] == 0 || kinds[game.RecordOutput] == 0 {
+		t.Fatalf("Expected two connections with input and output, got %v", kinds)
+	}
+
+	report, err := game.Replay(records, game.WithDataDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+	if report != "" {
+		t.Errorf("Expected the replay to match the recording, got:\n%s", report)
+	}
+
+	// A change to what was sent shows up in the report
+	for i := range records {
+		if records[i].Kind == game.RecordOutput && strings.Contains(records[i].Text, "Bob whispers") {
+			records[i].Text = "Bob whispers: Something else"
+			break
+		}
+	}
+	report, err = game.Replay(records, game.WithDataDir(t.TempDir()))
+	if err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+	if !strings.Contains(report, "- Bob whispers: Something else") || !strings.Contains(report, "+ Bob whispers: Just between us") {
+		t.Errorf("Expected the replay to report the changed whisper, got:\n%s", report)
+	}
+}
+
+func TestRecordingLeavesOutPasswords(t *testing.T) {
+	t.Parallel()
+	var rec recording
+	s := mudtest.NewServer(t, game.WithRecording(&rec), game.WithOwner("Alice", "secret"))
+
+	mallory := s.Connect()
+	mallory.Send("Alice")
+	mallory.ExpectEventually("Password:")
+	mallory.Send("letmein")
+	mallory.ExpectLine("Wrong password.")
+	alice := s.LoginWithPassword("Alice", "secret")
+	alice.Send("/say Hello")
+	alice.ExpectLine("Alice says: Hello")
+	alice.Send("/password hunter22")
+	alice.ExpectLine("Your password has been set.")
+	alice.Send("/quit")
+	alice.ExpectClosed()
+
+	for _, password := range []string{"letmein", "secret", "hunter22"} {
+		if strings.Contains(rec.String(), password) {
+			t.Errorf("Expected the recording to leave out %q, got:\n%s", password, rec.String())
+		}
+	}
+	records, err := game.ReadRecording(strings.NewReader(rec.String()))
+	if err != nil {
+		t.Fatalf("Failed to read the recording: %v", err)
+	}
+	var checks []string
+	end := len(records)
+	for i, record := range records {
+		if record.Kind == game.RecordPassword {
+			checks = append(checks, record.Text)
+		}
+		if record.Kind == game.RecordInput && record.Text == "/password ***" && end == len(records) {
+			// What follows cannot replay without the password
+			end = i
+		}
+	}
+	records = records[:end]
+	if strings.Join(checks, " ") != "wrong right" {
+		t.Errorf("Expected a wrong and then a right password in the recording, got %v", checks)
+	}
+
+	// Logins replay as the recorded checks went
+	report, err := game.Replay(records, game.WithDataDir(t.TempDir()), game.WithOwner("Alice", "unknown"))
+	if err != nil {
+		t.Fatalf("Failed to replay: %v", err)
+	}
+	if report != "" {
+		t.Errorf("Expected the replay to match the recording, got:\n%s", report)
+	}
+}
@@ -0,0 +1,50 @@
+package integrationtest
+
+import (
+	"strings"
+	"testing"
+
+	"mud/mudtest"
+)
+
+func TestReplyAndTells(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	// Nobody has whispered to Alice yet
+	alice.Send("/reply hello?")
+	alice.ExpectLine("Nobody has whispered to you yet.")
+
+	bob.Send("/whisper Alice are you there?")
+	bob.ExpectLines(1)
+	alice.ExpectLines(1)
+
+	alice.Send("/reply yes!")
+	alice.ExpectLine("You whispered to Bob: yes!")
+	bob.ExpectLine("Alice whispers: yes!")
+
+	alice.Send("/tells")
+	response := strings.Join(alice.ExpectLines(3), "\n")
+	expected := "Recent whispers:\nBob whispers: are you there?\nYou whispered to Bob: yes!"
+	if response != expected {
+		t.Errorf("Unexpected /tells output: got %q, want %q", response, expected)
+	}
+
+	// Bob leaves and Alice's next whisper waits for him
+	bob.Send("/quit")
+	alice.ExpectEventually("Bob has left the room.")
+
+	alice.Send("/reply see you later")
+	alice.ExpectLine("Bob is offline. Your message will be delivered when they log in.")
+
+	bob = s.Connect()
+	bob.Send("Bob")
+	responses := bob.ExpectEventually("Alice whispers: see you later")
+	if !strings.Contains(strings.Join(responses, "\n"), "You have 1 message(s) from while you were away:") {
+		t.Errorf("Bob did not get his offline messages: %v", responses)
+	}
+}
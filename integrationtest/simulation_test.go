@@ -0,0 +1,107 @@
+package integrationtest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mud/game"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden transcripts in testdata")
+
+// TestSimulations plays each testdata/*.sim script on a virtual clock and
+// compares the transcript with the .golden file next to it. Run with
+// -update to rewrite the golden files after a deliberate change.
+func TestSimulations(t *testing.T) {
+	scripts, err := filepath.Glob("testdata/*.sim")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, script := range scripts {
+		script := script
+		t.Run(strings.TrimSuffix(filepath.Base(script), ".sim"), func(t *testing.T) {
+			t.Parallel()
+			data, err := os.ReadFile(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sim := game.NewSimulation(game.WithOwner("Alice", "secret"))
+			defer sim.Game.Shutdown()
+			if err := sim.Run(string(data)); err != nil {
+				t.Fatal(err)
+			}
+			got := sim.Transcript()
+
+			golden := strings.TrimSuffix(script, ".sim") + ".golden"
+			if *update {
+				if err := os.WriteFile(golden, []byte(got), 0o644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != string(want) {
+				t.Errorf("The transcript differs from %s:\n%s", golden, diffLines(string(want), got))
+			}
+		})
+	}
+}
+
+// TestSimulationRandomness checks that commands drawing random numbers
+// from the game roll the same in every simulation.
+func TestSimulationRandomness(t *testing.T) {
+	t.Parallel()
+	roll := game.Command{
+		Name: "roll",
+		Handler: func(g *game.Game, session *game.Session, args *game.Args) []game.OutputEvent {
+			return []game.OutputEvent{{SessionID: session.ID, Message: fmt.Sprintf("You roll %d.", g.RandIntn(100)+1)}}
+		},
+	}
+	script := "Alice> Alice\nAlice> /roll\nAlice> /roll\nAlice> /roll\n"
+
+	var transcripts []string
+	for i := 0; i < 2; i++ {
+		sim := game.NewSimulation()
+		defer sim.Game.Shutdown()
+		if err := sim.Game.RegisterCommand(roll); err != nil {
+			t.Fatal(err)
+		}
+		if err := sim.Run(script); err != nil {
+			t.Fatal(err)
+		}
+		transcripts = append(transcripts, sim.Transcript())
+	}
+	if transcripts[0] != transcripts[1] {
+		t.Errorf("The rolls differ between runs:\n%s", diffLines(transcripts[0], transcripts[1]))
+	}
+	if strings.Count(transcripts[0], "You roll ") != 3 {
+		t.Errorf("Expected three rolls, got:\n%s", transcripts[0])
+	}
+}
+
+// diffLines lists the lines of got that differ from want, with their line
+// numbers.
+func diffLines(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	var diff strings.Builder
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			diff.WriteString(fmt.Sprintf("%d:\n- %s\n+ %s\n", i+1, w, g))
+		}
+	}
+	return diff.String()
+}
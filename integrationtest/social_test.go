@@ -0,0 +1,83 @@
+package integrationtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mud/game"
+	"mud/mudtest"
+)
+
+func TestEmotesAndSocials(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
+
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	charlie := s.Login("Charlie")
+	alice.ExpectEventually("Charlie has joined the room.")
+	bob.ExpectEventually("Charlie has joined the room.")
+
+	clients := []*mudtest.Client{alice, bob, charlie}
+	names := []string{"Alice", "Bob", "Charlie"}
+	steps := []struct {
+		client   *mudtest.Client
+		input    string
+		expected [][]string // per client: Alice, Bob, Charlie
+	}{
+		{alice, "/smile bob", [][]string{{"You smile at Bob."}, {"Alice smiles at you."}, {"Alice smiles at Bob."}}},
+		{alice, "/wave", [][]string{{"You wave."}, {"Alice waves."}, {"Alice waves."}}},
+		{alice, "/emote dances a jig.", [][]string{{"Alice dances a jig."}, {"Alice dances a jig."}, {"Alice dances a jig."}}},
+		{bob, "/hug", [][]string{nil, {"Missing <target>.", "Usage: /hug <target>"}, nil}},
+		{bob, "/hug zed", [][]string{nil, {"You don't see them here."}, nil}},
+		{charlie, "/bow 1.b", [][]string{{"Charlie bows before Bob."}, {"Charlie bows before you."}, {"You bow before Bob."}}},
+	}
+	for _, step := range steps {
+		step.client.Send(step.input)
+		for c, expected := range step.expected {
+			if len(expected) == 0 {
+				continue
+			}
+			responses := clients[c].ExpectLines(len(expected))
+			for i := range expected {
+				if responses[i] != expected[i] {
+					t.Errorf("Unexpected response %d for %s to %q: got %s, want %s", i+1, names[c], step.input, responses[i], expected[i])
+				}
+			}
+		}
+	}
+}
+
+func TestLoadSocials(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		socials string
+		problem string // in the error, or "" if the socials load
+	}{
+		{"valid", `[{"name": "nod", "self": "You nod.", "room": "$n nods.", "target_self": "You nod at $N.", "target": "$n nods at you.", "target_room": "$n nods at $N."}]`, ""},
+		{"untargeted", `[{"name": "nod", "self": "You nod.", "room": "$n nods."}]`, ""},
+		{"no name", `[{"self": "You nod."}]`, "needs a name and a self message"},
+		{"no target_self", `[{"name": "nod", "self": "You nod.", "target": "$n nods at you.", "target_room": "$n nods at $N."}]`, "nod needs all of target, target_self and target_room"},
+		{"no target_room", `[{"name": "nod", "self": "You nod.", "target_self": "You nod at $N.", "target": "$n nods at you."}]`, "nod needs all of target, target_self and target_room"},
+		{"not a list", `{"name": "nod"}`, "parsing socials"},
+	}
+	dir := t.TempDir()
+	for _, test := range tests {
+		path := filepath.Join(dir, strings.ReplaceAll(test.name, " ", "_")+".json")
+		if err := os.WriteFile(path, []byte(test.socials), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := game.LoadSocials(path)
+		switch {
+		case test.problem == "" && err != nil:
+			t.Errorf("%s: unexpected error: %v", test.name, err)
+		case test.problem != "" && (err == nil || !strings.Contains(err.Error(), test.problem)):
+			t.Errorf("%s: expected an error about %q, got %v", test.name, test.problem, err)
+		}
+	}
+}
@@ -3,67 +3,105 @@ package integrationtest
 import (
 	"strings"
 	"testing"
+
+	"mud/game"
+	"mud/mudtest"
 )
 
 func TestWhoCommand(t *testing.T) {
-	startServer(t)
-	defer stopServer()
+	t.Parallel()
+	s := mudtest.NewServer(t)
 
 	// Connect three users
-	aliceConn := connectTelnet(t)
-	defer aliceConn.Close()
-	bobConn := connectTelnet(t)
-	defer bobConn.Close()
-	charlieConn := connectTelnet(t)
-	defer charlieConn.Close()
-
-	// Set names for users
-	sendCommand(t, aliceConn, "Alice")
-	sendCommand(t, bobConn, "Bob")
-	sendCommand(t, charlieConn, "Charlie")
-
-	// Clear initial messages
-	readResponses(t, aliceConn, 4)  // Welcome + Who are you? + 2 join messages
-	readResponses(t, bobConn, 3)    // Welcome + Who are you? + 1 join message
-	readResponses(t, charlieConn, 2) // Welcome + Who are you?
+	alice := s.Login("Alice")
+	s.Login("Bob")
+	s.Login("Charlie")
+	alice.ExpectEventually("Charlie has joined the room.")
 
 	// Alice uses the /who command
-	sendCommand(t, aliceConn, "/who")
+	alice.Send("/who")
+	lines := alice.ExpectEventually("Charlie ")
+
+	if lines[0] != "Players online: 3" {
+		t.Errorf("Unexpected response format: %v", lines)
+	}
+	if len(lines) != 5 || !strings.HasPrefix(lines[1], "Name") {
+		t.Fatalf("Expected a header and 3 players, got %v", lines)
+	}
 
-	// Check Alice's response
-	aliceResponse := readResponses(t, aliceConn, 1)[0]
-	if !strings.HasPrefix(aliceResponse, "Users in this room:") {
-			t.Errorf("Unexpected response format: %s", aliceResponse)
+	// Players are listed by name with their class and room
+	for i, name := range []string{"Alice", "Bob", "Charlie"} {
+		fields := strings.Fields(lines[i+2])
+		if len(fields) < 3 || fields[0] != name || fields[1] != "Adventurer" || fields[2] != "Lobby" {
+			t.Errorf("Unexpected row for %s: %s", name, lines[i+2])
+		}
 	}
+}
 
-	// Extract the list of users
-	userList := strings.TrimPrefix(aliceResponse, "Users in this room: ")
-	users := strings.Split(userList, ", ")
+func TestWhoFilters(t *testing.T) {
+	t.Parallel()
+	s := mudtest.NewServer(t)
 
-	// Check that we have the correct number of users
-	if len(users) != 3 {
-			t.Errorf("Expected 3 users, got %d", len(users))
+	alice := s.Login("Alice")
+	bob := s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	bob.Send("/north")
+	bob.ExpectEventually("Town Square")
+	alice.ExpectEventually("Bob leaves north.")
+
+	// Only players in the same room
+	alice.Send("/who here")
+	lines := alice.ExpectEventually("Alice ")
+	if lines[0] != "Players online: 1" {
+		t.Errorf("Unexpected /who here response: %v", lines)
 	}
 
-	// Check that all expected users are in the list
-	expectedUsers := map[string]bool{
-			"Alice":   false,
-			"Bob":     false,
-			"Charlie": false,
+	// Only players in a named room
+	alice.Send("/who room town")
+	lines = alice.ExpectEventually("Bob ")
+	if lines[0] != "Players online: 1" {
+		t.Errorf("Unexpected /who room response: %v", lines)
 	}
 
-	for _, user := range users {
-			if _, exists := expectedUsers[user]; exists {
-					expectedUsers[user] = true
-			} else {
-					t.Errorf("Unexpected user in list: %s", user)
-			}
+	// Nobody matches a class nobody has
+	alice.Send("/who class mage")
+	alice.ExpectLine("No players match.")
+
+	alice.Send("/who sort level")
+	alice.ExpectLine("You can sort by name or idle.")
+
+	// Titles and AFK markers show up in the list
+	bob.Send("/title the Brave")
+	bob.ExpectEventually("You are now Bob the Brave.")
+	bob.Send("/afk making tea")
+	bob.ExpectEventually("You are now AFK.")
+
+	alice.Send("/who bo")
+	lines = alice.ExpectEventually("Bob ")
+	if row := lines[len(lines)-1]; !strings.HasSuffix(row, "the Brave [AFK]") {
+		t.Errorf("Unexpected row for Bob: %s", row)
+	}
+}
+
+func TestWhoStaff(t *testing.T) {
+	t.Parallel()
+	// Permissions every player has do not make them staff
+	s := mudtest.NewServer(t, game.WithOwner("Alice", "secret"), game.WithRolePermissions(game.RolePlayer, "dance"))
+
+	alice := s.LoginWithPassword("Alice", "secret")
+	s.Login("Bob")
+	alice.ExpectEventually("Bob has joined the room.")
+
+	alice.Send("/who")
+	lines := alice.ExpectEventually("Bob ")
+	if !strings.HasSuffix(lines[2], "[staff]") || strings.HasSuffix(lines[3], "[staff]") {
+		t.Errorf("Expected only Alice to be marked staff, got %v", lines)
 	}
 
-	// Check that all expected users were found
-	for user, found := range expectedUsers {
-			if !found {
-					t.Errorf("Expected user %s was not in the list", user)
-			}
+	alice.Send("/who staff")
+	lines = alice.ExpectEventually("Alice ")
+	if lines[0] != "Players online: 1" {
+		t.Errorf("Expected only Alice to be listed as staff, got %v", lines)
 	}
-}
\ No newline at end of file
+}
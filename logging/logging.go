@@ -0,0 +1,136 @@
+// Package logging sets up structured logging for the server. Every
+// subsystem logs through its own logger, whose level can be changed while
+// the server runs.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	output atomic.Pointer[slog.Handler]
+
+	mu           sync.Mutex
+	levels       = make(map[string]*slog.LevelVar) // maps subsystem to its level
+	defaultLevel = slog.LevelInfo
+)
+
+func init() {
+	var handler slog.Handler = slog.NewTextHandler(os.Stderr, nil)
+	output.Store(&handler)
+}
+
+// Setup makes every subsystem log to w, as JSON lines if json is set and as
+// key=value text otherwise. Subsystems without a level of their own log at
+// level and above.
+func Setup(w io.Writer, json bool, level slog.Level) {
+	options := &slog.HandlerOptions{Level: slog.LevelDebug}
+	var handler slog.Handler
+	if json {
+		handler = slog.NewJSONHandler(w, options)
+	} else {
+		handler = slog.NewTextHandler(w, options)
+	}
+	output.Store(&handler)
+
+	mu.Lock()
+	defer mu.Unlock()
+	defaultLevel = level
+	for _, v := range levels {
+		v.Set(level)
+	}
+}
+
+// For returns the logger of a subsystem such as "game" or "telnet".
+func For(subsystem string) *slog.Logger {
+	return slog.New(&subsystemHandler{level: levelVar(subsystem)}).With("subsystem", subsystem)
+}
+
+func levelVar(subsystem string) *slog.LevelVar {
+	mu.Lock()
+	defer mu.Unlock()
+	v, exists := levels[subsystem]
+	if !exists {
+		v = new(slog.LevelVar)
+		v.Set(defaultLevel)
+		levels[subsystem] = v
+	}
+	return v
+}
+
+// SetLevel changes the level a subsystem logs at.
+func SetLevel(subsystem string, level slog.Level) error {
+	mu.Lock()
+	defer mu.Unlock()
+	v, exists := levels[subsystem]
+	if !exists {
+		return fmt.Errorf("there is no subsystem called %s", subsystem)
+	}
+	v.Set(level)
+	return nil
+}
+
+// Levels returns the subsystems and their levels, sorted by subsystem.
+func Levels() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	var lines []string
+	for subsystem, v := range levels {
+		lines = append(lines, fmt.Sprintf("%s: %s", subsystem, v.Level()))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error".
+func ParseLevel(text string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.ToUpper(text))); err != nil {
+		return 0, fmt.Errorf("unknown log level %s", text)
+	}
+	return level, nil
+}
+
+// subsystemHandler filters records by the level of its subsystem and hands
+// the rest to the current output. Attributes and groups are applied to the
+// output when a record is handled, so Setup also affects existing loggers.
+type subsystemHandler struct {
+	level *slog.LevelVar
+	wrap  func(slog.Handler) slog.Handler
+}
+
+func (h *subsystemHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+func (h *subsystemHandler) Handle(ctx context.Context, record slog.Record) error {
+	handler := *output.Load()
+	if h.wrap != nil {
+		handler = h.wrap(handler)
+	}
+	return handler.Handle(ctx, record)
+}
+
+func (h *subsystemHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithAttrs(attrs) })
+}
+
+func (h *subsystemHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(handler slog.Handler) slog.Handler { return handler.WithGroup(name) })
+}
+
+func (h *subsystemHandler) with(next func(slog.Handler) slog.Handler) *subsystemHandler {
+	wrap := next
+	if previous := h.wrap; previous != nil {
+		wrap = func(handler slog.Handler) slog.Handler { return next(previous(handler)) }
+	}
+	return &subsystemHandler{level: h.level, wrap: wrap}
+}
@@ -1,12 +1,112 @@
 package main
 
 import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
 	"mud/game"
+	"mud/logging"
 	"mud/telnet"
 )
 
+var mainLog = logging.For("main")
+
 func main() {
-	gameInstance := game.NewGame()
+	bareCommands := flag.Bool("bare", false, "parse input without a leading / as a command")
+	dataDir := flag.String("data", "data", "directory to keep player accounts in")
+	socialsFile := flag.String("socials", "", "JSON file with extra socials")
+	owner := flag.String("owner", "", "name of the player who owns the server; they log in with the password in $MUD_OWNER_PASSWORD")
+	logPrivate := flag.Bool("log-private", false, "write the text of private messages to the log")
+	logFormat := flag.String("log-format", "text", "log as text or json")
+	logLevel := flag.String("log-level", "info", "log at debug, info, warn or error and above")
+	logLevels := flag.String("log-levels", "", "levels for single subsystems, such as telnet=debug,chat=warn")
+	metricsAddr := flag.String("metrics", "", "address such as localhost:9100 to serve /metrics and /healthz on")
+	maxPerIP := flag.Int("max-connections-per-ip", telnet.DefaultMaxConnectionsPerIP, "how many connections to accept from one IP address at once, 0 for no limit")
+	recordFile := flag.String("record", "", "file to record every session's input and output to, private messages included")
+	flag.Parse()
+
+	if err := setupLogging(*logFormat, *logLevel, *logLevels); err != nil {
+		mainLog.Error("Invalid logging flags", "err", err)
+		os.Exit(2)
+	}
+
+	ownerPassword := os.Getenv("MUD_OWNER_PASSWORD")
+	if *owner != "" && ownerPassword == "" {
+		mainLog.Warn("No owner password set; the owner can only log in if their account already has a password", "owner", *owner)
+	}
+
+	options := []game.Option{
+		game.WithBareCommands(*bareCommands),
+		game.WithDataDir(*dataDir),
+		game.WithOwner(*owner, ownerPassword),
+		game.WithPrivateMessageLogging(*logPrivate),
+	}
+	if *socialsFile != "" {
+		socials, err := game.LoadSocials(*socialsFile)
+		if err != nil {
+			mainLog.Error("Error loading socials", "err", err)
+			os.Exit(1)
+		}
+		options = append(options, game.WithSocials(socials))
+	}
+	if *recordFile != "" {
+		file, err := os.OpenFile(*recordFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			mainLog.Error("Error opening the recording", "err", err)
+			os.Exit(1)
+		}
+		defer file.Close()
+		options = append(options, game.WithRecording(file))
+	}
+
+	gameInstance := game.NewGame(options...)
 	server := telnet.NewServer(gameInstance)
-	server.Start()
-}
\ No newline at end of file
+	server.MaxConnectionsPerIP = *maxPerIP
+	go server.Start()
+	if *metricsAddr != "" {
+		go serveMetrics(*metricsAddr, gameInstance)
+	}
+
+	<-gameInstance.Done()
+	// Give the connections a moment to send their goodbyes.
+	time.Sleep(500 * time.Millisecond)
+	mainLog.Info("Server shut down")
+}
+
+// serveMetrics serves the game's metrics and health check over HTTP.
+func serveMetrics(addr string, g *game.Game) {
+	mainLog.Info("Serving metrics", "address", addr)
+	if err := http.ListenAndServe(addr, g.StatusHandler()); err != nil {
+		mainLog.Error("Error serving metrics", "err", err)
+	}
+}
+
+func setupLogging(format, level, subsystemLevels string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unknown log format %s", format)
+	}
+	defaultLevel, err := logging.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+	logging.Setup(os.Stderr, format == "json", defaultLevel)
+
+	for _, setting := range strings.Split(subsystemLevels, ",") {
+		if setting == "" {
+			continue
+		}
+		subsystem, levelName, _ := strings.Cut(setting, "=")
+		level, err := logging.ParseLevel(levelName)
+		if err != nil {
+			return err
+		}
+		if err := logging.SetLevel(subsystem, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,336 @@
+// Package mudtest runs a game in the test process and connects players to
+// it over telnet, for tests of how the server behaves end to end.
+//
+// Each Server listens on a port of its own and keeps its data in a
+// temporary directory, so tests using it can run in parallel:
+//
+//	s := mudtest.NewServer(t)
+//	alice, bob := s.Login("Alice"), s.Login("Bob")
+//	alice.Send("/say hi")
+//	bob.ExpectEventually("Alice says: hi")
+package mudtest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mud/game"
+	"mud/telnet"
+)
+
+var errClosed = errors.New("connection closed")
+
+// Timeout is how long a client waits for a line it expects.
+var Timeout = 5 * time.Second
+
+// Server is a game served over telnet on a local port.
+type Server struct {
+	Game *game.Game
+	Addr string // host:port to connect to
+
+	t        testing.TB
+	options  []game.Option
+	listener net.Listener
+
+	mu      sync.Mutex
+	clients []*Client
+	closed  bool
+}
+
+// NewServer starts a game with the given options and serves it on a free
+// local port until the test ends. Unless the options say otherwise, the
+// game keeps its data in a temporary directory.
+func NewServer(t testing.TB, options ...game.Option) *Server {
+	t.Helper()
+	return start(t, append([]game.Option{game.WithDataDir(t.TempDir())}, options...))
+}
+
+func start(t testing.TB, options []game.Option) *Server {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	s := &Server{
+		Game:     game.NewGame(options...),
+		Addr:     listener.Addr().String(),
+		t:        t,
+		options:  options,
+		listener: listener,
+	}
+	server := telnet.NewServer(s.Game)
+	// Every client connects from the same address
+	server.MaxConnectionsPerIP = 0
+	go server.Serve(listener)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Close shuts the game down and disconnects every client.
+func (s *Server) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	clients := s.clients
+	s.mu.Unlock()
+
+	s.listener.Close()
+	s.Game.Shutdown()
+	for _, c := range clients {
+		c.Close()
+	}
+}
+
+// Restart closes the server and starts a new one with the same options and
+// data, as if the machine had rebooted.
+func (s *Server) Restart() *Server {
+	s.t.Helper()
+	s.Close()
+	return start(s.t, s.options)
+}
+
+// Connect opens a connection that has not logged in yet.
+func (s *Server) Connect() *Client {
+	s.t.Helper()
+	conn, err := net.Dial("tcp", s.Addr)
+	if err != nil {
+		s.t.Fatalf("Failed to connect to %s: %v", s.Addr, err)
+	}
+	c := &Client{t: s.t, conn: conn, lines: make(chan string, 1024)}
+	go c.read()
+
+	s.mu.Lock()
+	s.clients = append(s.clients, c)
+	s.mu.Unlock()
+	return c
+}
+
+// Login connects and logs in as name, returning once the player has
+// joined the room and everything sent on the way has been read.
+func (s *Server) Login(name string) *Client {
+	s.t.Helper()
+	c := s.Connect()
+	c.Name = name
+	c.Send(name)
+	c.expectJoined()
+	return c
+}
+
+// LoginWithPassword is Login for an account that has a password, such as
+// the owner's.
+func (s *Server) LoginWithPassword(name, password string) *Client {
+	s.t.Helper()
+	c := s.Connect()
+	c.Name = name
+	c.Send(name)
+	c.ExpectEventually("Password:")
+	c.Send(password)
+	c.expectJoined()
+	return c
+}
+
+// Scenario plays a script with one step per line:
+//
+//	Alice> /say hi          Alice sends "/say hi"
+//	Bob< Alice says: hi     Bob is eventually sent a line containing the text
+//	Bob= Alice says: hi     the next line Bob is sent is exactly the text
+//
+// Blank lines and lines starting with # are skipped. Everyone named in the
+// script logs in before it starts, in the order they first appear, and
+// their clients are returned by name.
+func (s *Server) Scenario(script string) map[string]*Client {
+	s.t.Helper()
+	type step struct {
+		line   int
+		name   string
+		action byte
+		text   string
+	}
+	var steps []step
+	var names []string
+	for i, line := range strings.Split(script, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		at := strings.IndexAny(line, "><=")
+		if at <= 0 {
+			s.t.Fatalf("Scenario line %d: expected Name>, Name< or Name=, got %q", i+1, line)
+		}
+		name := line[:at]
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+		steps = append(steps, step{line: i + 1, name: name, action: line[at], text: strings.TrimPrefix(line[at+1:], " ")})
+	}
+
+	clients := make(map[string]*Client)
+	for _, name := range names {
+		clients[name] = s.Login(name)
+	}
+	for _, step := range steps {
+		c := clients[step.name]
+		switch step.action {
+		case '>':
+			c.Send(step.text)
+		case '<':
+			if lines, err := c.Await(step.text); err != nil {
+				s.t.Fatalf("Scenario line %d: %s never got %q (%v), got %v", step.line, step.name, step.text, err, lines)
+			}
+		case '=':
+			if line, err := c.next(); err != nil || line != step.text {
+				s.t.Fatalf("Scenario line %d: %s got %q (%v), want %q", step.line, step.name, line, err, step.text)
+			}
+		}
+	}
+	return clients
+}
+
+// Client is a telnet connection to a Server. Its methods that expect
+// output fail the test, so like testing.T.FailNow they must be called from
+// the test's goroutine; Await can be used from any goroutine.
+type Client struct {
+	Name string // who the client logged in as, if it did
+
+	t     testing.TB
+	conn  net.Conn
+	lines chan string // lines read, closed when the connection closes
+	err   error       // why the connection closed, set before lines is closed
+}
+
+// read queues the lines the server sends, without trailing whitespace.
+// Whatever follows the last newline, such as the telnet commands sent
+// when a session ends, is dropped.
+func (c *Client) read() {
+	defer close(c.lines)
+	reader := bufio.NewReader(c.conn)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			c.err = err
+			return
+		}
+		c.lines <- strings.TrimRight(line, " \r\n")
+	}
+}
+
+// next returns the next line, waiting up to Timeout for it.
+func (c *Client) next() (string, error) {
+	select {
+	case line, ok := <-c.lines:
+		if !ok {
+			return "", fmt.Errorf("%w: %v", errClosed, c.err)
+		}
+		return line, nil
+	case <-time.After(Timeout):
+		return "", fmt.Errorf("nothing received for %s", Timeout)
+	}
+}
+
+// Send sends a line of input.
+func (c *Client) Send(line string) {
+	c.t.Helper()
+	if _, err := fmt.Fprintf(c.conn, "%s\n", line); err != nil {
+		c.t.Fatalf("%s failed to send %q: %v", c, line, err)
+	}
+}
+
+// expectJoined waits for the client to be welcomed and to join the room.
+func (c *Client) expectJoined() {
+	c.t.Helper()
+	c.ExpectEventually(fmt.Sprintf("Welcome, %s!", c.Name))
+	c.ExpectEventually(fmt.Sprintf("%s has joined the room.", c.Name))
+}
+
+// ExpectLine fails the test unless the next line received is want.
+func (c *Client) ExpectLine(want string) {
+	c.t.Helper()
+	line, err := c.next()
+	if err != nil {
+		c.t.Fatalf("%s expected %q: %v", c, want, err)
+	}
+	if line != want {
+		c.t.Fatalf("%s got %q, want %q", c, line, want)
+	}
+}
+
+// ExpectLines returns the next n lines received, failing the test if they
+// do not arrive.
+func (c *Client) ExpectLines(n int) []string {
+	c.t.Helper()
+	var lines []string
+	for len(lines) < n {
+		line, err := c.next()
+		if err != nil {
+			c.t.Fatalf("%s expected %d lines, got %v: %v", c, n, lines, err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// ExpectEventually reads lines until one contains text and returns them
+// all, including the matching one. It fails the test if none does.
+func (c *Client) ExpectEventually(text string) []string {
+	c.t.Helper()
+	lines, err := c.Await(text)
+	if err != nil {
+		c.t.Fatalf("%s expected a line containing %q, got %v: %v", c, text, lines, err)
+	}
+	return lines
+}
+
+// Await reads lines until one contains text and returns them all,
+// including the matching one, or an error if none arrives.
+func (c *Client) Await(text string) ([]string, error) {
+	var lines []string
+	for {
+		line, err := c.next()
+		if err != nil {
+			return lines, err
+		}
+		lines = append(lines, line)
+		if strings.Contains(line, text) {
+			return lines, nil
+		}
+	}
+}
+
+// ExpectClosed reads until the server closes the connection, failing the
+// test if it does not.
+func (c *Client) ExpectClosed() {
+	c.t.Helper()
+	var lines []string
+	for {
+		line, err := c.next()
+		if err != nil {
+			if errors.Is(err, errClosed) {
+				return
+			}
+			c.t.Fatalf("%s expected the connection to close, got %v: %v", c, lines, err)
+		}
+		lines = append(lines, line)
+	}
+}
+
+// Close drops the connection.
+func (c *Client) Close() {
+	c.conn.Close()
+}
+
+func (c *Client) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.conn.LocalAddr().String()
+}
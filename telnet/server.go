@@ -2,50 +2,78 @@ package telnet
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"mud/game"
+	"mud/logging"
 )
 
+var telnetLog = logging.For("telnet")
+
+// writeTimeout is how long a client may take to accept output before it is
+// disconnected.
+const writeTimeout = 10 * time.Second
+
 const (
 	HOST = "localhost"
 	PORT = "2323"
 )
 
+// DefaultMaxConnectionsPerIP is how many connections a new Server accepts
+// from one IP address at once.
+const DefaultMaxConnectionsPerIP = 10
+
 type Server struct {
 	game *game.Game
+
+	// MaxConnectionsPerIP is how many connections the server accepts from
+	// one IP address at once, or 0 for no limit. It must not be changed
+	// once the server is serving.
+	MaxConnectionsPerIP int
+
+	mu          sync.Mutex
+	connections map[string]int // open connections by IP address
 }
 
 func NewServer(game *game.Game) *Server {
-	return &Server{game: game}
+	return &Server{
+		game:                game,
+		MaxConnectionsPerIP: DefaultMaxConnectionsPerIP,
+		connections:         make(map[string]int),
+	}
 }
 func (s *Server) handleConnection(conn net.Conn) {
 	defer conn.Close()
+	s.game.ConnectionOpened("telnet")
+	defer s.game.ConnectionClosed("telnet")
 
 	sessionID := conn.RemoteAddr().String()
 	fmt.Fprintf(conn, "Welcome to the MUD server!\n")
 
-	reader := bufio.NewReader(conn)
+	// Room for the longest line the game accepts and its line ending
+	reader := bufio.NewReaderSize(conn, game.MaxLineLength+2)
 
-	// Send the name to the game and wait for confirmation
+	// Tell the game about the connection and wait for confirmation
 	responseChan := make(chan bool)
-	s.game.GetInputChannel() <- game.InputEvent{SessionID: sessionID, Input: "", ResponseChan: responseChan}
+	s.game.GetInputChannel() <- game.InputEvent{SessionID: sessionID, Kind: game.InputConnect, ResponseChan: responseChan}
 
 	// Wait for the game to process the new session
 	if !<-responseChan {
-		log.Printf("Failed to create session for %s", sessionID)
+		telnetLog.Error("Failed to create session", "session", sessionID)
 		return
 	}
 
 	// Get a dedicated output channel for this session
 	outputChan, exists := s.game.GetOutputChannel(sessionID)
 	if !exists {
-		log.Printf("Failed to get output channel for session %s", sessionID)
+		telnetLog.Error("Failed to get output channel", "session", sessionID)
 		return
 	}
 
@@ -60,31 +88,42 @@ func (s *Server) handleConnection(conn net.Conn) {
 		// Remove the deadline for blocking reads
 		conn.SetReadDeadline(time.Time{})
 
-		input, err := reader.ReadString('\n')
+		input, err := readLine(reader)
 		if err != nil {
 			if err == io.EOF || isClosedConnError(err) {
 				// Connection was closed
 				break
 			} else {
 				// This is a real error
-				log.Printf("Error reading from connection: %v", err)
+				telnetLog.Error("Error reading from connection", "session", sessionID, "err", err)
 				break
 			}
 		}
 
-		input = strings.TrimSpace(input)
-		if input == "" {
-			continue
-		}
+		// The game tidies up the line itself, since text written in its
+		// editor keeps its blank lines and indentation
+		input = strings.TrimRight(input, "\r\n")
 
-		log.Printf("Received from %s: %s", sessionID, input)
-		s.game.GetInputChannel() <- game.InputEvent{SessionID: sessionID, Input: input}
+		s.game.SubmitInput(game.InputEvent{SessionID: sessionID, Input: input})
 	}
 
-	// Signal handleOutgoing to stop
+	// Signal handleOutgoing to stop and let the game clean up the session
 	close(quitChan)
+	s.game.GetInputChannel() <- game.InputEvent{SessionID: sessionID, Kind: game.InputDisconnect}
+
+	telnetLog.Info("Connection closed", "session", sessionID)
+}
 
-	log.Printf("Connection closed for %s", sessionID)
+// readLine reads a line of input. Once a line is too long for the reader's
+// buffer the rest of it is skipped, and the part that fit is returned for
+// the game to turn down.
+func readLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadSlice('\n')
+	kept := string(line)
+	for err == bufio.ErrBufferFull {
+		_, err = reader.ReadSlice('\n')
+	}
+	return kept, err
 }
 
 // isClosedConnError checks if the error is due to a closed connection
@@ -96,24 +135,34 @@ func isClosedConnError(err error) bool {
 }
 
 func (s *Server) handleOutgoing(conn net.Conn, outputChan <-chan game.OutputEvent, quitChan <-chan bool) {
+	// Whatever the game still sends once the connection is gone is thrown
+	// away, until it closes the channel when the session has ended.
+	defer func() {
+		for range outputChan {
+		}
+	}()
 	for {
 		select {
 		case <-quitChan:
 			return
 		case output, ok := <-outputChan:
 			if !ok {
+				// The session has left the game
+				conn.Close()
 				return
 			}
+			conn.SetWriteDeadline(time.Now().Add(writeTimeout))
 			_, err := fmt.Fprintf(conn, "%s\n", output.Message)
 			if err != nil {
-				log.Printf("Error writing to connection: %v", err)
+				telnetLog.Error("Error writing to connection", "err", err)
+				conn.Close()
 				return
 			}
 			if output.Quit {
 				// Send Telnet End of Session command
 				_, err := conn.Write([]byte{255, 244, 255, 253, 6})
 				if err != nil {
-					log.Printf("Error sending End of Session command: %v", err)
+					telnetLog.Error("Error sending End of Session command", "err", err)
 				}
 				// Flush the connection
 				if flusher, ok := conn.(interface{ Flush() error }); ok {
@@ -127,23 +176,78 @@ func (s *Server) handleOutgoing(conn net.Conn, outputChan <-chan game.OutputEven
 	}
 }
 
+// Start listens on HOST:PORT and serves connections, exiting the program
+// if it cannot listen.
 func (s *Server) Start() {
 	listener, err := net.Listen("tcp", HOST+":"+PORT)
 	if err != nil {
-		log.Fatalf("Error starting server: %v", err)
+		telnetLog.Error("Error starting server", "err", err)
+		os.Exit(1)
 	}
-	defer listener.Close()
-
-	log.Printf("Server listening on %s:%s", HOST, PORT)
+	telnetLog.Info("Server listening", "address", HOST+":"+PORT)
+	s.Serve(listener)
+}
 
+// Serve handles the connections accepted from listener until it is closed.
+func (s *Server) Serve(listener net.Listener) {
+	defer listener.Close()
 	for {
 		conn, err := listener.Accept()
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
 		if err != nil {
-			log.Printf("Error accepting connection: %v", err)
+			telnetLog.Error("Error accepting connection", "err", err)
 			continue
 		}
-		log.Printf("New connection from %s", conn.RemoteAddr().String())
+		ip := remoteIP(conn)
+		if !s.admit(ip) {
+			telnetLog.Warn("Refusing connection", "session", conn.RemoteAddr().String(), "reason", "too many connections")
+			go refuse(conn, "There are too many connections from your address. Please try again later.")
+			continue
+		}
+		telnetLog.Info("New connection", "session", conn.RemoteAddr().String())
 
-		go s.handleConnection(conn)
+		go func() {
+			defer s.release(ip)
+			s.handleConnection(conn)
+		}()
 	}
 }
+
+// admit counts a new connection from ip, reporting whether the server
+// takes it.
+func (s *Server) admit(ip string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.MaxConnectionsPerIP > 0 && s.connections[ip] >= s.MaxConnectionsPerIP {
+		return false
+	}
+	s.connections[ip]++
+	return true
+}
+
+// release forgets a connection from ip that has closed.
+func (s *Server) release(ip string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.connections[ip]--; s.connections[ip] <= 0 {
+		delete(s.connections, ip)
+	}
+}
+
+// remoteIP returns the IP address a connection comes from.
+func remoteIP(conn net.Conn) string {
+	addr := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// refuse tells a connection the server will not take it and closes it.
+func refuse(conn net.Conn, reason string) {
+	defer conn.Close()
+	conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	fmt.Fprintf(conn, "%s\n", reason)
+}